@@ -0,0 +1,270 @@
+package bovasdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+)
+
+// Account предоставляет информацию о счете мерчанта: баланс и лимиты. Эндпоинты /v1/account и
+// /v1/account/balance и формат их ответов не описаны в документации Bova и являются предположением.
+type Account struct {
+	transport *transport
+}
+
+func accountNew(transport *transport) *Account {
+	return &Account{transport: transport}
+}
+
+// Balance - баланс счета в одной валюте.
+type Balance struct {
+	Currency CurrencyEnum `json:"currency"`
+	// Available - сумма, доступная для новых выплат
+	Available string `json:"available"`
+	// Held - сумма, заблокированная под выплаты в обработке
+	Held string `json:"held"`
+	// Reserved - резерв, недоступный для выплат
+	Reserved string `json:"reserved"`
+}
+
+// BalanceResponse представляет тело ответа API для баланса счета. Формат ответа не подтвержден документацией Bova.
+type BalanceResponse struct {
+	ResultCode string    `json:"result_code"`
+	Payload    []Balance `json:"payload"`
+}
+
+// Find возвращает баланс в валюте currency.
+func (r *BalanceResponse) Find(currency CurrencyEnum) (Balance, bool) {
+	for _, b := range r.Payload {
+		if b.Currency == currency {
+			return b, true
+		}
+	}
+	return Balance{}, false
+}
+
+// AccountLimit - лимит счета для валюты и направления. Пустые суммы означают отсутствие ограничения.
+type AccountLimit struct {
+	Currency  CurrencyEnum `json:"currency"`
+	Direction Direction    `json:"direction"`
+	MinAmount string       `json:"min_amount"`
+	MaxAmount string       `json:"max_amount"`
+	// DailyLimit и DailyUsed - лимит оборота за сутки и уже использованная его часть
+	DailyLimit string `json:"daily_limit"`
+	DailyUsed  string `json:"daily_used"`
+}
+
+// AccountInfoResponse представляет тело ответа API для информации о счете. Формат ответа не подтвержден документацией Bova.
+type AccountInfoResponse struct {
+	ResultCode string `json:"result_code"`
+	Payload    struct {
+		ID     string         `json:"id"`
+		Name   string         `json:"name"`
+		Limits []AccountLimit `json:"limits"`
+	} `json:"payload"`
+}
+
+// GetBalance получает баланс счета по всем валютам.
+// Эндпоинт GET /v1/account/balance не описан в документации Bova и может отсутствовать, тогда возвращается ошибка API.
+func (a *Account) GetBalance(ctx context.Context, opts ...CallOption) (*BalanceResponse, error) {
+	return execute[BalanceResponse](ctx, a.transport, apiCall{
+		method: http.MethodGet,
+		path:   "/v1/account/balance",
+	}, opts...)
+}
+
+// GetAccountInfo получает информацию о счете и его лимитах.
+// Эндпоинт GET /v1/account не описан в документации Bova и может отсутствовать, тогда возвращается ошибка API.
+func (a *Account) GetAccountInfo(ctx context.Context, opts ...CallOption) (*AccountInfoResponse, error) {
+	return execute[AccountInfoResponse](ctx, a.transport, apiCall{
+		method: http.MethodGet,
+		path:   "/v1/account",
+	}, opts...)
+}
+
+// PayoutQuoteFunc рассчитывает суммы выплаты. FiatAmount и ServiceCommission должны быть в валюте баланса.
+type PayoutQuoteFunc func(req MassTransactionRequest) (*Quote, error)
+
+// PreflightCurrency - итог проверки выплат в одной валюте.
+type PreflightCurrency struct {
+	Currency   CurrencyEnum `json:"currency"`
+	Count      int          `json:"count"`
+	Amount     string       `json:"amount"`
+	Commission string       `json:"commission"`
+	Required   string       `json:"required"`
+	Available  string       `json:"available"`
+	// Shortfall - недостающая сумма, "0.00" если средств хватает
+	Shortfall string `json:"shortfall"`
+	// LimitViolations - нарушения лимитов счета на выплаты
+	LimitViolations []string `json:"limit_violations,omitempty"`
+}
+
+// PreflightReport - результат проверки запланированных выплат по балансу и лимитам.
+type PreflightReport struct {
+	// OK равен true, если средств хватает во всех валютах и лимиты не нарушены
+	OK         bool                `json:"ok"`
+	Currencies []PreflightCurrency `json:"currencies"`
+}
+
+// PreflightPayouts проверяет, что доступного баланса хватает на выплаты вместе с комиссией и что выплаты
+// укладываются в лимиты счета: минимальную и максимальную сумму и остаток суточного лимита.
+// Суммы и комиссия берутся из расчета quote, если quote равен nil, сумма выплаты берется из запроса без комиссии.
+func (a *Account) PreflightPayouts(ctx context.Context, reqs []MassTransactionRequest, quote PayoutQuoteFunc) (*PreflightReport, error) {
+	type total struct {
+		count              int
+		amount, commission *big.Rat
+		amounts            []*big.Rat
+		merchantIDs        []string
+	}
+	totals := make(map[CurrencyEnum]*total)
+	for _, req := range reqs {
+		t, ok := totals[req.Currency]
+		if !ok {
+			t = &total{amount: new(big.Rat), commission: new(big.Rat)}
+			totals[req.Currency] = t
+		}
+		amount, commission, err := payoutAmounts(req, quote)
+		if err != nil {
+			return nil, err
+		}
+		t.count++
+		t.amount.Add(t.amount, amount)
+		t.commission.Add(t.commission, commission)
+		t.amounts = append(t.amounts, amount)
+		t.merchantIDs = append(t.merchantIDs, req.MerchantID)
+	}
+
+	balance, err := a.GetBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := a.GetAccountInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PreflightReport{OK: true}
+	for currency, t := range totals {
+		available := new(big.Rat)
+		if b, ok := balance.Find(currency); ok {
+			if available, err = parseAmount(b.Available); err != nil {
+				return nil, fmt.Errorf("error parsing %s balance: %v", currency, err)
+			}
+		}
+
+		required := new(big.Rat).Add(t.amount, t.commission)
+		shortfall := new(big.Rat).Sub(required, available)
+		if shortfall.Sign() > 0 {
+			report.OK = false
+		} else {
+			shortfall.SetInt64(0)
+		}
+
+		var violations []string
+		for _, limit := range info.Payload.Limits {
+			if limit.Currency != currency || limit.Direction != DirectionPayout {
+				continue
+			}
+			v, err := limit.check(t.amounts, t.merchantIDs, t.amount)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing %s limit: %v", currency, err)
+			}
+			violations = append(violations, v...)
+		}
+		if len(violations) > 0 {
+			report.OK = false
+		}
+
+		report.Currencies = append(report.Currencies, PreflightCurrency{
+			Currency:        currency,
+			Count:           t.count,
+			Amount:          formatAmount(t.amount),
+			Commission:      formatAmount(t.commission),
+			Required:        formatAmount(required),
+			Available:       formatAmount(available),
+			Shortfall:       formatAmount(shortfall),
+			LimitViolations: violations,
+		})
+	}
+	sort.Slice(report.Currencies, func(i, j int) bool { return report.Currencies[i].Currency < report.Currencies[j].Currency })
+	return report, nil
+}
+
+// payoutAmounts возвращает сумму выплаты и комиссию в одной валюте.
+func payoutAmounts(req MassTransactionRequest, quote PayoutQuoteFunc) (amount, commission *big.Rat, err error) {
+	if quote == nil {
+		return new(big.Rat).SetInt64(int64(req.Amount)), new(big.Rat), nil
+	}
+	q, err := quote(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error estimating commission for %s: %v", req.MerchantID, err)
+	}
+	if amount, err = parseAmount(q.FiatAmount); err != nil {
+		return nil, nil, fmt.Errorf("error parsing fiat amount for %s: %v", req.MerchantID, err)
+	}
+	if commission, err = parseAmount(q.ServiceCommission); err != nil {
+		return nil, nil, fmt.Errorf("error parsing commission for %s: %v", req.MerchantID, err)
+	}
+	return amount, commission, nil
+}
+
+// check возвращает нарушения лимита для сумм выплат amounts и их итога total.
+func (l AccountLimit) check(amounts []*big.Rat, merchantIDs []string, total *big.Rat) ([]string, error) {
+	limit := func(s string) (*big.Rat, error) {
+		if s == "" {
+			return nil, nil
+		}
+		return parseAmount(s)
+	}
+	minAmount, err := limit(l.MinAmount)
+	if err != nil {
+		return nil, err
+	}
+	maxAmount, err := limit(l.MaxAmount)
+	if err != nil {
+		return nil, err
+	}
+	dailyLimit, err := limit(l.DailyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []string
+	for i, amount := range amounts {
+		if minAmount != nil && amount.Cmp(minAmount) < 0 {
+			violations = append(violations, fmt.Sprintf("%s: amount %s is below min %s", merchantIDs[i], formatAmount(amount), formatAmount(minAmount)))
+		}
+		if maxAmount != nil && amount.Cmp(maxAmount) > 0 {
+			violations = append(violations, fmt.Sprintf("%s: amount %s is above max %s", merchantIDs[i], formatAmount(amount), formatAmount(maxAmount)))
+		}
+	}
+	if dailyLimit != nil {
+		used := new(big.Rat)
+		if l.DailyUsed != "" {
+			if used, err = parseAmount(l.DailyUsed); err != nil {
+				return nil, err
+			}
+		}
+		remaining := new(big.Rat).Sub(dailyLimit, used)
+		if total.Cmp(remaining) > 0 {
+			violations = append(violations, fmt.Sprintf("total %s exceeds daily limit remainder %s", formatAmount(total), formatAmount(remaining)))
+		}
+	}
+	return violations, nil
+}
+
+// parseAmount разбирает денежную сумму из ответа API.
+func parseAmount(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(normalizeAmount(s))
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %q", s)
+	}
+	return r, nil
+}
+
+// formatAmount форматирует сумму с двумя знаками после запятой.
+func formatAmount(r *big.Rat) string {
+	return r.FloatString(2)
+}
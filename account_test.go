@@ -0,0 +1,78 @@
+package bovasdk_test
+
+import (
+	"context"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestPreflightPayouts tests balance lookup and the payout pre-flight check
+func TestPreflightPayouts(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	server.SetBalance(bovasdk.Balance{Currency: bovasdk.RUB, Available: "1000.50", Held: "200", Reserved: "0"})
+	server.SetBalance(bovasdk.Balance{Currency: bovasdk.UZS, Available: "100"})
+	server.SetAccountLimits(bovasdk.AccountLimit{Currency: bovasdk.RUB, Direction: bovasdk.DirectionPayout, MaxAmount: "50000"})
+	sdk := newTestSDK(t, server)
+	ctx := context.Background()
+
+	balance, err := sdk.Account.GetBalance(ctx)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if rub, ok := balance.Find(bovasdk.RUB); !ok || rub.Held != "200" {
+		t.Errorf("Find(RUB) = %+v, %v", rub, ok)
+	}
+	info, err := sdk.Account.GetAccountInfo(ctx)
+	if err != nil || len(info.Payload.Limits) != 1 || info.Payload.Limits[0].MaxAmount != "50000" {
+		t.Errorf("GetAccountInfo() = %+v, %v", info, err)
+	}
+
+	reqs := []bovasdk.MassTransactionRequest{
+		*bovasdk.NewMassTransactionRequest("user", "p-1", "4111111111111111", "https://example.com", 500, bovasdk.RUB, bovasdk.Card),
+		*bovasdk.NewMassTransactionRequest("user", "p-2", "4111111111111111", "https://example.com", 480, bovasdk.RUB, bovasdk.Card),
+		*bovasdk.NewMassTransactionRequest("user", "p-3", "8600123412341234", "https://example.com", 90, bovasdk.UZS, bovasdk.Card),
+	}
+	// комиссия 2% от суммы, для UZS сумма в запросе пересчитывается по курсу 0.5
+	schedule := bovasdk.NewFeeSchedule(
+		bovasdk.CommissionRule{Currency: bovasdk.RUB, Method: bovasdk.Card, Direction: bovasdk.DirectionPayout, Percent: "2"},
+		bovasdk.CommissionRule{Currency: bovasdk.UZS, Method: bovasdk.Card, Direction: bovasdk.DirectionPayout, Percent: "2", Rate: "0.5"},
+	)
+
+	report, err := sdk.Account.PreflightPayouts(ctx, reqs, schedule.PayoutQuote)
+	if err != nil {
+		t.Fatalf("PreflightPayouts() error = %v", err)
+	}
+	if !report.OK || len(report.Currencies) != 2 {
+		t.Fatalf("PreflightPayouts() = %+v, want enough funds in RUB and UZS", report)
+	}
+	rub, uzs := report.Currencies[0], report.Currencies[1]
+	if rub.Required != "999.60" || rub.Shortfall != "0.00" || rub.Count != 2 {
+		t.Errorf("RUB = %+v, want required 999.60 without shortfall", rub)
+	}
+	if uzs.Amount != "45.00" || uzs.Commission != "0.90" || uzs.Required != "45.90" || uzs.Shortfall != "0.00" {
+		t.Errorf("UZS = %+v, want amount and commission after rate", uzs)
+	}
+
+	server.SetAccountLimits(
+		bovasdk.AccountLimit{Currency: bovasdk.RUB, Direction: bovasdk.DirectionPayout, MinAmount: "490", DailyLimit: "10000", DailyUsed: "9500"},
+		bovasdk.AccountLimit{Currency: bovasdk.UZS, Direction: bovasdk.DirectionDeposit, MaxAmount: "1"},
+	)
+	report, err = sdk.Account.PreflightPayouts(ctx, reqs, nil)
+	if err != nil {
+		t.Fatalf("PreflightPayouts() with limits error = %v", err)
+	}
+	rub, uzs = report.Currencies[0], report.Currencies[1]
+	if report.OK || len(rub.LimitViolations) != 2 || len(uzs.LimitViolations) != 0 {
+		t.Errorf("PreflightPayouts() with limits = %+v, want min and daily limit violations in RUB", report)
+	}
+	server.SetAccountLimits()
+
+	reqs = append(reqs, *bovasdk.NewMassTransactionRequest("user", "p-4", "4111111111111111", "https://example.com", 100, bovasdk.KRW, bovasdk.AccountNumber))
+	report, err = sdk.Account.PreflightPayouts(ctx, reqs, nil)
+	if err != nil || report.OK || report.Currencies[0].Currency != bovasdk.KRW || report.Currencies[0].Shortfall != "100.00" {
+		t.Errorf("PreflightPayouts() without KRW balance = %+v, %v", report, err)
+	}
+}
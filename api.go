@@ -1,6 +1,8 @@
 package bovasdk
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,17 +14,29 @@ type BovaApi struct {
 	secret          string
 	logger          Logger
 	client          *http.Client
+	transport       *transport
 	P2P             *P2P
 	MassTransaction *MassTransaction
+	Account         *Account
 	Encoder         *Encoder
+	environment     Environment
 }
 
 // BovaApiBuilder помогает построить экземпляр BovaApi.
 type BovaApiBuilder struct {
 	apiURL string
-	secret string
-	client *http.Client
-	logger Logger
+	// environment - окружение, объявленное через Environment, сбрасывается вызовом ApiURL
+	environment *Environment
+	secret      string
+	client      *http.Client
+	logger      Logger
+	middlewares []Middleware
+	// secretEnv - окружение, для которого выпущен ключ, если оно задано
+	secretEnv     *Environment
+	allowMismatch bool
+	strictEnums   bool
+	sbpBanks      *BankDirectory
+	capabilities  bool
 }
 
 // NewBovaApiBuilder создает новый экземпляр BovaApiBuilder.
@@ -33,6 +47,30 @@ func NewBovaApiBuilder() *BovaApiBuilder {
 // ApiURL устанавливает URL API.
 func (b *BovaApiBuilder) ApiURL(apiURL string) *BovaApiBuilder {
 	b.apiURL = apiURL
+	b.environment = nil
+	return b
+}
+
+// Environment устанавливает окружение и его URL, например bovasdk.Sandbox. Для прокси или mock сервера
+// окружение можно объявить через WithURL: Environment(bovasdk.Sandbox.WithURL(server.URL)).
+func (b *BovaApiBuilder) Environment(env Environment) *BovaApiBuilder {
+	b.apiURL = env.URL
+	b.environment = &env
+	return b
+}
+
+// SecretEnvironment помечает, для какого окружения выпущен ключ. Если окружение ключа не совпадает
+// с окружением API, запросы, изменяющие данные (выплаты, платежи, отмены), отклоняются с ErrEnvironmentMismatch.
+// По самому ключу окружение не определить, ключи Bova - hex строки без префикса окружения, поэтому
+// без SecretEnvironment проверка не выполняется.
+func (b *BovaApiBuilder) SecretEnvironment(env Environment) *BovaApiBuilder {
+	b.secretEnv = &env
+	return b
+}
+
+// AllowEnvironmentMismatch отключает проверку окружения ключа, например для проверки ключа на другом стенде.
+func (b *BovaApiBuilder) AllowEnvironmentMismatch() *BovaApiBuilder {
+	b.allowMismatch = true
 	return b
 }
 
@@ -54,6 +92,43 @@ func (b *BovaApiBuilder) Logger(logger Logger) *BovaApiBuilder {
 	return b
 }
 
+// Middleware добавляет Middleware для всех запросов к API. Middleware вызываются в порядке добавления,
+// подпись запроса выполняется после них.
+func (b *BovaApiBuilder) Middleware(middlewares ...Middleware) *BovaApiBuilder {
+	b.middlewares = append(b.middlewares, middlewares...)
+	return b
+}
+
+// StrictEnums включает строгую проверку перечислений для всех вызовов клиента, ее можно
+// переопределить для вызова через WithStrictEnums. По умолчанию неизвестные значения, например
+// новые статусы Bova, проходят без ошибок.
+func (b *BovaApiBuilder) StrictEnums() *BovaApiBuilder {
+	b.strictEnums = true
+	return b
+}
+
+// ValidateSbpBanks включает проверку sbp_bank_name выплат по справочнику directory перед отправкой,
+// при nil используется SbpBanks. Название, которое Resolve находит однозначно, заменяется названием
+// из справочника, иначе выплата не отправляется и возвращается *UnknownBankError. По умолчанию проверка
+// выключена и название отправляется как есть: поставляемый справочник неполный, его лучше сначала обновить
+// через BankDirectory.Refresh.
+func (b *BovaApiBuilder) ValidateSbpBanks(directory *BankDirectory) *BovaApiBuilder {
+	if directory == nil {
+		directory = sbpBanks
+	}
+	b.sbpBanks = directory
+	return b
+}
+
+// ValidateCapabilities включает проверку запросов на создание по таблице возможностей (RegisterCapability):
+// запрос с методом, который таблица не поддерживает для валюты, без обязательных полей или вне лимитов
+// не отправляется. По умолчанию проверка выключена, потому что таблица по умолчанию не подтверждена
+// документацией Bova, ее можно переопределить для вызова через WithCapabilityValidation.
+func (b *BovaApiBuilder) ValidateCapabilities() *BovaApiBuilder {
+	b.capabilities = true
+	return b
+}
+
 // Build строит и возвращает экземпляр BovaApi.
 func (b *BovaApiBuilder) Build() (*BovaApi, error) {
 	if b.secret == "" {
@@ -81,15 +156,68 @@ func (b *BovaApiBuilder) Build() (*BovaApi, error) {
 		}
 	}
 
+	environment := environmentOf(b.apiURL)
+	if b.environment != nil {
+		if err := b.environment.checkDeclared(); err != nil {
+			return nil, err
+		}
+		environment = *b.environment
+	}
+	middlewares := b.middlewares
+	if b.secretEnv != nil && b.secretEnv.Name != environment.Name && !b.allowMismatch {
+		middlewares = append([]Middleware{environmentGuard(environment, *b.secretEnv)}, middlewares...)
+	}
+
 	encoder := NewEncoder(b.secret)
+	transport := newTransport(b.apiURL, encoder, b.client, middlewares...)
+	transport.strictEnums = b.strictEnums
+	transport.sbpBanks = b.sbpBanks
+	transport.capabilities = b.capabilities
 
 	return &BovaApi{
 		apiURL:          b.apiURL,
 		secret:          b.secret,
 		client:          b.client,
 		logger:          b.logger,
+		transport:       transport,
+		environment:     environment,
 		Encoder:         encoder,
-		P2P:             p2pNew(b.apiURL, encoder, b.client),
-		MassTransaction: massTransactionNew(b.apiURL, encoder, b.client),
+		P2P:             p2pNew(transport),
+		MassTransaction: massTransactionNew(transport),
+		Account:         accountNew(transport),
 	}, nil
 }
+
+// Environment возвращает окружение API. Для URL, не совпадающего с известными окружениями, Name равно "custom".
+func (api *BovaApi) Environment() Environment {
+	return api.environment
+}
+
+// Do выполняет произвольный запрос к API с подписью, логированием, Middleware и типизацией ошибок SDK,
+// например для новых эндпоинтов, которые еще не поддержаны SDK. path может содержать query строку.
+// body может быть nil, *MultipartForm, []byte или json.RawMessage с готовым JSON, либо значением для json.Marshal.
+// Если out не nil, JSON ответа декодируется в out. Возвращаемый ответ содержит код, заголовки и прочитанное тело,
+// для кода, отличного от 200, он возвращается вместе с *APIError.
+func (api *BovaApi) Do(ctx context.Context, method, path string, body, out interface{}, opts ...CallOption) (*http.Response, error) {
+	call := apiCall{method: method, path: path}
+	switch b := body.(type) {
+	case nil:
+	case *MultipartForm:
+		call.setBody = newMultipartBody(b).setBody
+	case []byte:
+		call.json = json.RawMessage(b)
+	default:
+		call.json = b
+	}
+
+	resp, respBody, err := api.transport.do(ctx, call, opts...)
+	if err != nil {
+		return resp, err
+	}
+	if out != nil {
+		if err = json.Unmarshal(respBody, out); err != nil {
+			return resp, fmt.Errorf("error Unmarshal response: %v", err)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,302 @@
+package bovasdk
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// bundledSbpBanks - справочник участников СБП, поставляемый с SDK. Это неполный снимок крупных банков,
+// а названия в нем - предположение о том, как их ожидает Bova: документации со списком допустимых
+// значений sbp_bank_name нет. Актуальный список загружается через BankDirectory.Refresh.
+//
+//go:embed sbp_banks.json
+var bundledSbpBanks []byte
+
+// Bank - банк-участник СБП. Name - название, которое ожидает Bova в sbp_bank_name.
+type Bank struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// BankMatch - результат нечеткого поиска банка, Score от 0 до 1.
+type BankMatch struct {
+	Bank  Bank    `json:"bank"`
+	Score float64 `json:"score"`
+}
+
+// UnknownBankError возвращается для названия банка, которого нет в справочнике.
+type UnknownBankError struct {
+	Input       string
+	Suggestions []string
+}
+
+func (e *UnknownBankError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown sbp bank: %q", e.Input)
+	}
+	return fmt.Sprintf("unknown sbp bank: %q, did you mean: %s", e.Input, strings.Join(e.Suggestions, ", "))
+}
+
+const (
+	// minBankScore - минимальная оценка, при которой банк попадает в подсказки
+	minBankScore = 0.6
+	// resolveBankScore - минимальная оценка, при которой Resolve выбирает банк без точного совпадения
+	resolveBankScore   = 0.8
+	maxBankSuggestions = 3
+)
+
+// BankDirectory - справочник банков СБП с поиском по названию, id и синонимам.
+type BankDirectory struct {
+	mu    sync.RWMutex
+	banks []Bank
+	// index - нормализованные название, синонимы и id банка
+	index map[string]int
+}
+
+// NewBankDirectory создает справочник из banks.
+func NewBankDirectory(banks ...Bank) *BankDirectory {
+	d := &BankDirectory{}
+	d.Replace(banks)
+	return d
+}
+
+var sbpBanks = mustBundledBankDirectory()
+
+func mustBundledBankDirectory() *BankDirectory {
+	d := NewBankDirectory()
+	if err := d.Load(bytes.NewReader(bundledSbpBanks)); err != nil {
+		panic(fmt.Sprintf("invalid bundled sbp banks: %v", err))
+	}
+	return d
+}
+
+// SbpBanks возвращает справочник банков СБП, поставляемый с SDK.
+func SbpBanks() *BankDirectory {
+	return sbpBanks
+}
+
+// Replace заменяет содержимое справочника.
+func (d *BankDirectory) Replace(banks []Bank) {
+	index := make(map[string]int)
+	copied := make([]Bank, 0, len(banks))
+	for _, b := range banks {
+		b.Aliases = append([]string(nil), b.Aliases...)
+		copied = append(copied, b)
+		i := len(copied) - 1
+		for _, key := range append([]string{b.ID, b.Name}, b.Aliases...) {
+			if k := normalizeBankName(key); k != "" {
+				if _, ok := index[k]; !ok {
+					index[k] = i
+				}
+			}
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.banks = copied
+	d.index = index
+}
+
+// Load заменяет содержимое справочника JSON массивом банков в формате Bank, например из файла.
+func (d *BankDirectory) Load(r io.Reader) error {
+	var banks []Bank
+	if err := json.NewDecoder(r).Decode(&banks); err != nil {
+		return fmt.Errorf("error decoding banks: %v", err)
+	}
+	for i, b := range banks {
+		if b.Name == "" {
+			return fmt.Errorf("bank %d has no name", i)
+		}
+	}
+	d.Replace(banks)
+	return nil
+}
+
+// Refresh заменяет содержимое справочника списком банков из API. Синонимы банков с тем же id сохраняются.
+// Эндпоинт /v1/sbp_banks не описан в документации Bova и может отсутствовать, тогда возвращается ошибка API.
+func (d *BankDirectory) Refresh(ctx context.Context, api *BovaApi, opts ...CallOption) error {
+	resp, err := api.MassTransaction.GetSbpBanks(ctx, opts...)
+	if err != nil {
+		return err
+	}
+
+	aliases := make(map[string][]string)
+	for _, b := range d.List() {
+		if b.ID != "" {
+			aliases[b.ID] = b.Aliases
+		}
+	}
+	banks := make([]Bank, 0, len(resp.Payload))
+	for _, b := range resp.Payload {
+		if len(b.Aliases) == 0 {
+			b.Aliases = aliases[b.ID]
+		}
+		banks = append(banks, b)
+	}
+	d.Replace(banks)
+	return nil
+}
+
+// List возвращает все банки справочника.
+func (d *BankDirectory) List() []Bank {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]Bank(nil), d.banks...)
+}
+
+// Lookup ищет банк по точному названию, синониму или id без учета регистра и пунктуации.
+func (d *BankDirectory) Lookup(input string) (Bank, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if i, ok := d.index[normalizeBankName(input)]; ok {
+		return d.banks[i], true
+	}
+	return Bank{}, false
+}
+
+// Search возвращает до limit банков, похожих на input, по убыванию оценки. limit <= 0 означает без ограничения.
+func (d *BankDirectory) Search(input string, limit int) []BankMatch {
+	query := normalizeBankName(input)
+	if query == "" {
+		return nil
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var matches []BankMatch
+	for _, b := range d.banks {
+		best := 0.0
+		for _, key := range append([]string{b.ID, b.Name}, b.Aliases...) {
+			best = max(best, bankScore(query, normalizeBankName(key)))
+		}
+		if best >= minBankScore {
+			matches = append(matches, BankMatch{Bank: b, Score: best})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Resolve возвращает банк по пользовательскому вводу: точное совпадение или единственный уверенный
+// результат поиска. Иначе возвращается *UnknownBankError с подсказками.
+func (d *BankDirectory) Resolve(input string) (Bank, error) {
+	if b, ok := d.Lookup(input); ok {
+		return b, nil
+	}
+	matches := d.Search(input, maxBankSuggestions)
+	if len(matches) > 0 && matches[0].Score >= resolveBankScore && (len(matches) == 1 || matches[1].Score < matches[0].Score) {
+		return matches[0].Bank, nil
+	}
+	return Bank{}, &UnknownBankError{Input: input, Suggestions: bankNames(matches)}
+}
+
+// resolveSbpBank заменяет sbp_bank_name выплаты названием банка из справочника, если Resolve нашел
+// банк однозначно, иначе возвращает *UnknownBankError.
+func (d *BankDirectory) resolveSbpBank(m *MassTransactionRequest) error {
+	if !m.isPhonePayout() || m.SbpBankName == nil || *m.SbpBankName == "" {
+		return nil
+	}
+	b, err := d.Resolve(*m.SbpBankName)
+	if err != nil {
+		return err
+	}
+	m.SbpBankName = &b.Name
+	return nil
+}
+
+func bankNames(matches []BankMatch) []string {
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, m.Bank.Name)
+	}
+	return names
+}
+
+// bankStopWords не различают банки и отбрасываются при сравнении названий.
+var bankStopWords = map[string]struct{}{
+	"банк": {}, "bank": {}, "пао": {}, "ао": {}, "акб": {}, "кб": {}, "ооо": {}, "нко": {}, "pjsc": {}, "jsc": {},
+}
+
+// normalizeBankName приводит название к нижнему регистру, заменяет ё на е, убирает пунктуацию и слова вроде "банк".
+func normalizeBankName(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	kept := make([]string, 0, len(words))
+	for _, w := range words {
+		if _, ok := bankStopWords[w]; !ok {
+			kept = append(kept, w)
+		}
+	}
+	// название может состоять только из стоп-слов
+	if len(kept) == 0 {
+		kept = words
+	}
+	return strings.Join(kept, " ")
+}
+
+// bankScore оценивает похожесть нормализованных строк: префикс, вхождение или расстояние Левенштейна.
+func bankScore(query, key string) float64 {
+	switch {
+	case key == "":
+		return 0
+	case query == key:
+		return 1
+	case strings.HasPrefix(key, query) && len([]rune(query)) >= 3:
+		return 0.9
+	case strings.Contains(key, query) && len([]rune(query)) >= 3:
+		return 0.8
+	}
+	q, k := []rune(query), []rune(key)
+	return 1 - float64(levenshtein(q, k))/float64(max(len(q), len(k)))
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// SbpBanksResponse представляет тело ответа API для списка банков СБП.
+type SbpBanksResponse struct {
+	ResultCode string `json:"result_code"`
+	Payload    []Bank `json:"payload"`
+}
+
+// GetSbpBanks получает список банков-участников СБП, доступных для выплат.
+func (mt *MassTransaction) GetSbpBanks(ctx context.Context, opts ...CallOption) (*SbpBanksResponse, error) {
+	return execute[SbpBanksResponse](ctx, mt.transport, apiCall{
+		method: http.MethodGet,
+		path:   "/v1/sbp_banks",
+	}, opts...)
+}
@@ -0,0 +1,90 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestBankDirectory tests lookup, fuzzy search, payout validation and refresh of the SBP bank directory
+func TestBankDirectory(t *testing.T) {
+	banks := bovasdk.SbpBanks()
+	for input, want := range map[string]string{
+		"Сбер":            "Сбербанк",
+		"  сбербанк ":     "Сбербанк",
+		"100000000111":    "Сбербанк",
+		"тинькофф":        "Т-Банк",
+		"АО «Альфа-Банк»": "Альфа-Банк",
+		"Сбрбанк":         "Сбербанк",
+		"райфайзен":       "Райффайзенбанк",
+		"почта":           "Почта Банк",
+	} {
+		bank, err := banks.Resolve(input)
+		if err != nil || bank.Name != want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", input, bank.Name, err, want)
+		}
+	}
+
+	var unknown *bovasdk.UnknownBankError
+	if _, err := banks.Resolve("Банк Марса"); !errors.As(err, &unknown) {
+		t.Errorf("Resolve() error = %v, want UnknownBankError", err)
+	}
+	if matches := banks.Search("альфа", 1); len(matches) != 1 || matches[0].Bank.ID != "100000000008" {
+		t.Errorf("Search(альфа) = %+v", matches)
+	}
+
+	req := bovasdk.NewMassTransactionRequest("user", "payout-1", "79161234567", "https://example.com/callback", 1000, bovasdk.RUB, bovasdk.Sbp)
+	if err := req.WithSbpBankName("Банк Марса").Validate(); err != nil {
+		t.Errorf("Validate() error = %v, bank is not checked without ValidateSbpBanks", err)
+	}
+
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+	ctx := context.Background()
+	// по умолчанию название отправляется как есть
+	created, err := sdk.MassTransaction.CreateMassTransaction(ctx, *req.WithSbpBankName("Тинькофф"))
+	if err != nil || created.Payload.SbpBankName != "Тинькофф" {
+		t.Errorf("CreateMassTransaction() = %+v, %v, want bank name unchanged", created, err)
+	}
+
+	logger, _ := bovasdk.NewLogger(false, "error")
+	validating, err := bovasdk.NewBovaApiBuilder().ApiURL(server.URL).Secret(testSecret).Logger(logger).ValidateSbpBanks(nil).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.MerchantID = "payout-2"
+	if created, err = validating.MassTransaction.CreateMassTransaction(ctx, *req.WithSbpBankName("Сбер")); err != nil || created.Payload.SbpBankName != "Сбербанк" {
+		t.Errorf("CreateMassTransaction(Сбер) = %+v, %v, want resolved Сбербанк", created, err)
+	}
+	req.MerchantID = "payout-3"
+	if _, err = validating.MassTransaction.CreateMassTransaction(ctx, *req.WithSbpBankName("Банк Марса")); !errors.As(err, &unknown) {
+		t.Errorf("CreateMassTransaction(Банк Марса) error = %v, want UnknownBankError before sending", err)
+	}
+	if _, err = validating.MassTransaction.CreateMassTransaction(ctx, *req, bovasdk.WithSbpBankValidation(false)); err != nil {
+		t.Errorf("CreateMassTransaction() without validation error = %v", err)
+	}
+
+	server.SetSbpBanks(bovasdk.Bank{ID: "100000000111", Name: "СберБанк"}, bovasdk.Bank{ID: "100000000999", Name: "Новый Банк"})
+	directory := bovasdk.NewBankDirectory(bovasdk.Bank{ID: "100000000111", Name: "Сбербанк", Aliases: []string{"Сбер"}})
+	if err = directory.Refresh(ctx, sdk); err != nil {
+		t.Fatal(err)
+	}
+	if bank, ok := directory.Lookup("сбер"); !ok || bank.Name != "СберБанк" {
+		t.Errorf("Lookup(сбер) after Refresh = %+v, %v, want aliases kept", bank, ok)
+	}
+	if _, ok := directory.Lookup("новый банк"); !ok || len(directory.List()) != 2 {
+		t.Errorf("Refresh() list = %+v", directory.List())
+	}
+
+	if err = directory.Load(strings.NewReader(`[{"id":"1","name":"Тест Банк","aliases":["Тест"]}]`)); err != nil {
+		t.Fatal(err)
+	}
+	if bank, ok := directory.Lookup("тест"); !ok || bank.ID != "1" {
+		t.Errorf("Lookup(тест) after Load = %+v, %v", bank, ok)
+	}
+}
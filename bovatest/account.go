@@ -0,0 +1,62 @@
+package bovatest
+
+import (
+	"net/http"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+)
+
+// SetBalance задает баланс счета в валюте.
+func (s *Server) SetBalance(balance bovasdk.Balance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, b := range s.balances {
+		if b.Currency == balance.Currency {
+			s.balances[i] = balance
+			return
+		}
+	}
+	s.balances = append(s.balances, balance)
+}
+
+// SetAccountLimits задает лимиты счета.
+func (s *Server) SetAccountLimits(limits ...bovasdk.AccountLimit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = append([]bovasdk.AccountLimit(nil), limits...)
+}
+
+func (s *Server) getBalance(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, bovasdk.BalanceResponse{ResultCode: "ok", Payload: append([]bovasdk.Balance{}, s.balances...)})
+}
+
+func (s *Server) getAccount(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp bovasdk.AccountInfoResponse
+	resp.ResultCode = "ok"
+	resp.Payload.ID = "merchant-1"
+	resp.Payload.Name = "bovatest"
+	resp.Payload.Limits = append([]bovasdk.AccountLimit{}, s.limits...)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// SetSbpBanks задает список банков СБП, который возвращает сервер.
+func (s *Server) SetSbpBanks(banks ...bovasdk.Bank) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sbpBanks = append([]bovasdk.Bank(nil), banks...)
+}
+
+func (s *Server) getSbpBanks(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	banks := s.sbpBanks
+	if banks == nil {
+		banks = bovasdk.SbpBanks().List()
+	}
+	writeJSON(w, http.StatusOK, bovasdk.SbpBanksResponse{ResultCode: "ok", Payload: banks})
+}
@@ -0,0 +1,63 @@
+package bovatest
+
+import (
+	"net/http"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+)
+
+// Маршруты отмены /v1/p2p_transactions/{id}/cancel и /v1/mass_transactions/{id}/cancel и формат отказа
+// повторяют предположения SDK, в документации Bova их нет, поэтому поведение mock сервера не проверено на API.
+
+// cancelResponse - тело отказа в отмене со статусом транзакции.
+type cancelResponse struct {
+	ResultCode string `json:"result_code"`
+	Message    string `json:"message"`
+	Payload    struct {
+		State string `json:"state"`
+	} `json:"payload"`
+}
+
+// cancelP2P отменяет p2p транзакцию в статусе waiting_payment, переводя ее в failed.
+func (s *Server) cancelP2P(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.p2p[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if tx.Payload.State != bovasdk.WaitingPayment {
+		writeCancelRejected(w, string(tx.Payload.State))
+		return
+	}
+	tx.Payload.State = bovasdk.Failed
+	tx.Payload.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, tx)
+}
+
+// cancelMass отменяет выплату в статусе waiting_payment, переводя ее в failed.
+func (s *Server) cancelMass(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.mass[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if tx.Payload.State != string(bovasdk.WaitingPayment) {
+		writeCancelRejected(w, tx.Payload.State)
+		return
+	}
+	tx.Payload.State = string(bovasdk.Failed)
+	tx.Payload.UpdatedAt = now()
+	writeJSON(w, http.StatusOK, tx)
+}
+
+func writeCancelRejected(w http.ResponseWriter, state string) {
+	resp := cancelResponse{ResultCode: "error", Message: "transaction cannot be canceled in state " + state}
+	resp.Payload.State = state
+	writeJSON(w, http.StatusUnprocessableEntity, resp)
+}
@@ -0,0 +1,117 @@
+package bovatest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+)
+
+// maxDisputeUploadSize ограничивает размер загружаемых в mock сервер файлов.
+const maxDisputeUploadSize = 32 << 20
+
+// SetDisputeState меняет статус диспута. Возвращает false, если диспут не найден.
+func (s *Server) SetDisputeState(id int, state bovasdk.DisputeStateEnum) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.disputes[id]
+	if ok {
+		d.State = state
+		d.UpdatedAt = now()
+	}
+	return ok
+}
+
+func (s *Server) createDispute(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(maxDisputeUploadSize); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if _, _, err := r.FormFile("p2p_dispute[proof_image]"); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "proof_image is required")
+		return
+	}
+	amount, err := strconv.Atoi(r.FormValue("p2p_dispute[amount]"))
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid amount")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transactionID := r.FormValue("transaction_id")
+	tx, ok := s.p2p[transactionID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+
+	repeated := false
+	for _, d := range s.disputes {
+		if d.P2PTx.ID == transactionID {
+			repeated = true
+		}
+	}
+
+	s.seq++
+	d := &bovasdk.P2PDispute{
+		ID:         s.seq,
+		State:      bovasdk.DisputeOpened,
+		Repeated:   repeated,
+		Amount:     amount,
+		ProofImage: s.URL + "/proof/" + strconv.Itoa(s.seq),
+		CreatedAt:  now(),
+	}
+	d.UpdatedAt = d.CreatedAt
+	d.P2PTx.ID = transactionID
+	d.P2PTx.MerchantID = tx.Payload.MerchantID
+	d.P2PTx.State = string(tx.Payload.State)
+	d.P2PTx.Amount = tx.Payload.Amount
+	s.disputes[d.ID] = d
+
+	writeJSON(w, http.StatusOK, bovasdk.P2PDisputeResponse{Data: *d, Status: "ok"})
+}
+
+func (s *Server) getDispute(w http.ResponseWriter, rawID string) {
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "dispute not found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.disputes[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "dispute not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, bovasdk.P2PDisputeResponse{Data: *d, Status: "ok"})
+}
+
+func (s *Server) listDisputes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []bovasdk.P2PDispute{}
+	for _, d := range s.disputes {
+		if v := q.Get("transaction_id"); v != "" && d.P2PTx.ID != v {
+			continue
+		}
+		if v := q.Get("state"); v != "" && string(d.State) != v {
+			continue
+		}
+		if v := q.Get("repeated"); v != "" && strconv.FormatBool(d.Repeated) != v {
+			continue
+		}
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	writeJSON(w, http.StatusOK, bovasdk.P2PDisputeListResponse{Data: list, Status: "ok"})
+}
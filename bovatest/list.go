@@ -0,0 +1,98 @@
+package bovatest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+)
+
+// ThrottleNext заставляет сервер ответить 429 с Retry-After: 0 на следующие n запросов.
+func (s *Server) ThrottleNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttled = n
+}
+
+func (s *Server) throttle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.throttled > 0 {
+		s.throttled--
+		return true
+	}
+	return false
+}
+
+func (s *Server) listP2P(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []bovasdk.P2PTransactionPayload{}
+	for _, id := range s.p2pOrder {
+		tx := s.p2p[id].Payload
+		if matchTransaction(q, tx.MerchantID, string(tx.State), string(tx.Currency), string(tx.PaymentMethod), tx.CreatedAt) {
+			list = append(list, tx)
+		}
+	}
+	page, meta := paginate(q, len(list))
+	writeJSON(w, http.StatusOK, bovasdk.P2PTransactionListResponse{ResultCode: "ok", Payload: list[page[0]:page[1]], Meta: meta})
+}
+
+func (s *Server) listMass(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []bovasdk.MassTransactionPayload{}
+	for _, id := range s.massOrder {
+		tx := s.mass[id].Payload
+		if matchTransaction(q, tx.MerchantId, tx.State, tx.Currency, string(tx.PaymentMethod), tx.CreatedAt) {
+			list = append(list, tx)
+		}
+	}
+	page, meta := paginate(q, len(list))
+	writeJSON(w, http.StatusOK, bovasdk.MassTransactionListResponse{ResultCode: "ok", Payload: list[page[0]:page[1]], Meta: meta})
+}
+
+func matchTransaction(q url.Values, merchantID, state, currency, paymentMethod, createdAt string) bool {
+	for key, value := range map[string]string{
+		"merchant_id":    merchantID,
+		"state":          state,
+		"currency":       currency,
+		"payment_method": paymentMethod,
+	} {
+		if v := q.Get(key); v != "" && v != value {
+			return false
+		}
+	}
+	// даты в RFC3339 UTC сравниваются как строки
+	if v := q.Get("created_from"); v != "" && createdAt < v {
+		return false
+	}
+	if v := q.Get("created_to"); v != "" && createdAt > v {
+		return false
+	}
+	return true
+}
+
+// paginate возвращает границы страницы в списке из total элементов и meta ответа.
+func paginate(q url.Values, total int) ([2]int, bovasdk.ListMeta) {
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 100
+	}
+
+	meta := bovasdk.ListMeta{Page: page, PerPage: perPage, TotalCount: total, TotalPages: (total + perPage - 1) / perPage}
+	from := min((page-1)*perPage, total)
+	to := min(from+perPage, total)
+	return [2]int{from, to}, meta
+}
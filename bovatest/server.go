@@ -0,0 +1,276 @@
+// Package bovatest предоставляет in-memory mock сервер Bova API для тестов.
+package bovatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+)
+
+// Server представляет mock сервер Bova API, хранящий транзакции в памяти.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	encoder  *bovasdk.Encoder
+	seq      int
+	p2p      map[string]*bovasdk.P2PTransactionResponse
+	mass     map[string]*bovasdk.MassTransactionResponse
+	disputes map[int]*bovasdk.P2PDispute
+	// p2pOrder и massOrder хранят порядок добавления транзакций для списков
+	p2pOrder  []string
+	massOrder []string
+	// throttled - число следующих запросов, на которые сервер ответит 429
+	throttled int
+	balances  []bovasdk.Balance
+	limits    []bovasdk.AccountLimit
+	// sbpBanks - список банков СБП, по умолчанию справочник SDK
+	sbpBanks []bovasdk.Bank
+}
+
+// NewServer запускает mock сервер. Если secret не пустой, сервер проверяет подпись JSON запросов.
+func NewServer(secret string) *Server {
+	s := &Server{
+		p2p:      make(map[string]*bovasdk.P2PTransactionResponse),
+		mass:     make(map[string]*bovasdk.MassTransactionResponse),
+		disputes: make(map[int]*bovasdk.P2PDispute),
+	}
+	if secret != "" {
+		s.encoder = bovasdk.NewEncoder(secret)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.route))
+	return s
+}
+
+// AddP2PTransaction добавляет p2p транзакцию в хранилище сервера.
+func (s *Server) AddP2PTransaction(resp bovasdk.P2PTransactionResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.storeP2P(&resp)
+}
+
+// AddMassTransaction добавляет массовую транзакцию в хранилище сервера.
+func (s *Server) AddMassTransaction(resp bovasdk.MassTransactionResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.storeMass(&resp)
+}
+
+// SetP2PState меняет статус p2p транзакции. Возвращает false, если транзакция не найдена.
+func (s *Server) SetP2PState(id string, state bovasdk.TransactionStateEnum) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.p2p[id]
+	if ok {
+		tx.Payload.State = state
+		tx.Payload.UpdatedAt = now()
+	}
+	return ok
+}
+
+// SetMassState меняет статус массовой транзакции. Возвращает false, если транзакция не найдена.
+func (s *Server) SetMassState(id string, state bovasdk.TransactionStateEnum) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.mass[id]
+	if ok {
+		tx.Payload.State = string(state)
+		tx.Payload.UpdatedAt = now()
+	}
+	return ok
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if s.encoder != nil && r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if !s.encoder.VerifySignature(body, r.Header.Get("Signature")) {
+			writeError(w, http.StatusUnauthorized, "invalid signature")
+			return
+		}
+	}
+
+	if s.throttle() {
+		w.Header().Set("Retry-After", "0")
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/account/balance":
+		s.getBalance(w)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/account":
+		s.getAccount(w)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/sbp_banks":
+		s.getSbpBanks(w)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/p2p_transactions":
+		s.listP2P(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/mass_transactions":
+		s.listMass(w, r)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/p2p_transactions/") && strings.HasSuffix(r.URL.Path, "/cancel"):
+		s.cancelP2P(w, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/p2p_transactions/"), "/cancel"))
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/mass_transactions/") && strings.HasSuffix(r.URL.Path, "/cancel"):
+		s.cancelMass(w, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/mass_transactions/"), "/cancel"))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/p2p_transactions":
+		s.createP2P(w, body)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/p2p_transactions/"):
+		s.getP2P(w, strings.TrimPrefix(r.URL.Path, "/v1/p2p_transactions/"))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/mass_transactions":
+		s.createMass(w, body)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/mass_transactions/"):
+		s.getMass(w, strings.TrimPrefix(r.URL.Path, "/v1/mass_transactions/"))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/p2p_disputes/from_client":
+		s.createDispute(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/p2p_disputes":
+		s.listDisputes(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/p2p_disputes/"):
+		s.getDispute(w, strings.TrimPrefix(r.URL.Path, "/v1/p2p_disputes/"))
+	default:
+		writeError(w, http.StatusNotFound, "route not found")
+	}
+}
+
+func (s *Server) createP2P(w http.ResponseWriter, body []byte) {
+	var req bovasdk.P2PTransactionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp bovasdk.P2PTransactionResponse
+	resp.ResultCode = "ok"
+	resp.Payload.ID = s.nextID("p2p")
+	resp.Payload.MerchantID = req.MerchantID
+	resp.Payload.Currency = req.Currency
+	resp.Payload.PaymentMethod = req.PaymentMethod
+	resp.Payload.State = bovasdk.WaitingPayment
+	resp.Payload.Amount = strconv.Itoa(req.Amount)
+	resp.Payload.FiatAmount = strconv.Itoa(req.Amount)
+	resp.Payload.TotalAmount = strconv.Itoa(req.Amount)
+	resp.Payload.ServiceCommission = "0"
+	resp.Payload.Rate = "1"
+	resp.Payload.CallbackURL = req.CallbackURL
+	resp.Payload.FormURL = fmt.Sprintf("%s/form/%s", s.URL, resp.Payload.ID)
+	resp.Payload.CreatedAt = now()
+	resp.Payload.UpdatedAt = resp.Payload.CreatedAt
+	s.storeP2P(&resp)
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getP2P(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.p2p[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, tx)
+}
+
+func (s *Server) createMass(w http.ResponseWriter, body []byte) {
+	var req bovasdk.MassTransactionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp bovasdk.MassTransactionResponse
+	resp.ResultCode = "ok"
+	resp.Payload.ID = s.nextID("mass")
+	resp.Payload.MerchantId = req.MerchantID
+	resp.Payload.State = string(bovasdk.WaitingPayment)
+	resp.Payload.Currency = string(req.Currency)
+	resp.Payload.PaymentMethod = req.PaymentMethod
+	resp.Payload.CallBackUrl = req.CallbackURL
+	resp.Payload.Amount = strconv.Itoa(req.Amount)
+	resp.Payload.FiatAmount = strconv.Itoa(req.Amount)
+	resp.Payload.TotalAmount = strconv.Itoa(req.Amount)
+	resp.Payload.ServiceCommission = "0"
+	resp.Payload.Rate = "1"
+	resp.Payload.RecipientCard = req.ToCard
+	if req.BankName != nil {
+		resp.Payload.BankName = *req.BankName
+	}
+	if req.SbpBankName != nil {
+		resp.Payload.SbpBankName = *req.SbpBankName
+	}
+	resp.Payload.CreatedAt = now()
+	resp.Payload.UpdatedAt = resp.Payload.CreatedAt
+	s.storeMass(&resp)
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getMass(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.mass[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, tx)
+}
+
+// storeP2P сохраняет p2p транзакцию, вызывается под s.mu.
+func (s *Server) storeP2P(resp *bovasdk.P2PTransactionResponse) {
+	if _, ok := s.p2p[resp.Payload.ID]; !ok {
+		s.p2pOrder = append(s.p2pOrder, resp.Payload.ID)
+	}
+	s.p2p[resp.Payload.ID] = resp
+}
+
+// storeMass сохраняет массовую транзакцию, вызывается под s.mu.
+func (s *Server) storeMass(resp *bovasdk.MassTransactionResponse) {
+	if _, ok := s.mass[resp.Payload.ID]; !ok {
+		s.massOrder = append(s.massOrder, resp.Payload.ID)
+	}
+	s.mass[resp.Payload.ID] = resp
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s-%d", prefix, s.seq)
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"result_code": "error", "message": message})
+}
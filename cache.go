@@ -0,0 +1,322 @@
+package bovasdk
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CacheBackend - хранилище кэша с семантикой Redis GET/SET EX.
+// Для нескольких экземпляров сервиса его можно реализовать поверх Redis, локально используется NewLRUCache.
+type CacheBackend interface {
+	// Get возвращает значение и false, если ключа нет или срок его жизни истек.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set сохраняет значение на время ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete удаляет ключ.
+	Delete(ctx context.Context, key string) error
+}
+
+// defaultCacheFetchTimeout - максимальное время запроса к API при промахе кэша, если оно не задано через WithFetchTimeout.
+const defaultCacheFetchTimeout = 30 * time.Second
+
+// CacheTTLPolicy определяет время жизни записи кэша по статусу транзакции.
+// Значение 0 и меньше означает, что ответ не кэшируется.
+type CacheTTLPolicy func(state TransactionStateEnum) time.Duration
+
+// DefaultCacheTTLPolicy кэширует надолго только статусы повторного диспута, которые больше не меняются,
+// ожидающие оплаты - на пару секунд, остальные - на 10 секунд. Successed и Failed не кэшируются надолго,
+// потому что по транзакции может быть открыт диспут.
+func DefaultCacheTTLPolicy(state TransactionStateEnum) time.Duration {
+	switch {
+	case state == WaitingPayment:
+		return 2 * time.Second
+	case state == RepeatedAcceptedSuccessed, state == RepeatedClosedFailed:
+		return time.Hour
+	default:
+		return 10 * time.Second
+	}
+}
+
+// TransactionCache - кэш чтения для GetP2PTransaction и GetMassTransaction.
+// Одновременные запросы одной транзакции объединяются в один запрос к API.
+type TransactionCache struct {
+	p2p          *P2P
+	mt           *MassTransaction
+	backend      CacheBackend
+	ttl          CacheTTLPolicy
+	fetchTimeout time.Duration
+	group        singleflightGroup
+
+	mu sync.Mutex
+	// generations - номер инвалидации ключа, по нему запрос, начатый до Invalidate*, не сохраняет устаревший ответ
+	generations map[string]uint64
+	// fetching - число выполняющихся запросов по ключу, номер инвалидации хранится, пока они не завершатся
+	fetching map[string]int
+}
+
+// NewTransactionCache создает кэш поверх API. Если backend равен nil, используется LRU кэш на 10000 записей.
+func NewTransactionCache(api *BovaApi, backend CacheBackend) *TransactionCache {
+	if backend == nil {
+		backend = NewLRUCache(10000)
+	}
+	return &TransactionCache{
+		p2p:          api.P2P,
+		mt:           api.MassTransaction,
+		backend:      backend,
+		ttl:          DefaultCacheTTLPolicy,
+		fetchTimeout: defaultCacheFetchTimeout,
+		generations:  make(map[string]uint64),
+		fetching:     make(map[string]int),
+	}
+}
+
+// WithTTLPolicy задает время жизни записей по статусу и возвращает обновленный кэш
+func (c *TransactionCache) WithTTLPolicy(ttl CacheTTLPolicy) *TransactionCache {
+	c.ttl = ttl
+	return c
+}
+
+// WithFetchTimeout задает максимальное время запроса к API при промахе кэша, по умолчанию 30 секунд,
+// и возвращает обновленный кэш
+func (c *TransactionCache) WithFetchTimeout(timeout time.Duration) *TransactionCache {
+	c.fetchTimeout = timeout
+	return c
+}
+
+// GetP2PTransaction возвращает p2p транзакцию из кэша или из API.
+func (c *TransactionCache) GetP2PTransaction(ctx context.Context, transactionID string) (*P2PTransactionResponse, error) {
+	var response P2PTransactionResponse
+	err := c.get(ctx, "p2p:"+transactionID, &response, func(ctx context.Context) (interface{}, TransactionStateEnum, error) {
+		resp, err := c.p2p.GetP2PTransaction(ctx, transactionID)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp, resp.Payload.State, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetMassTransaction возвращает массовую транзакцию из кэша или из API.
+func (c *TransactionCache) GetMassTransaction(ctx context.Context, transactionID string) (*MassTransactionResponse, error) {
+	var response MassTransactionResponse
+	err := c.get(ctx, "mass:"+transactionID, &response, func(ctx context.Context) (interface{}, TransactionStateEnum, error) {
+		resp, err := c.mt.GetMassTransaction(ctx, transactionID)
+		if err != nil {
+			return nil, "", err
+		}
+		return resp, TransactionStateEnum(resp.Payload.State), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// InvalidateP2PTransaction удаляет p2p транзакцию из кэша, например при получении callback'а.
+// Ответ запроса к API, начатого до вызова, в кэш не сохраняется.
+func (c *TransactionCache) InvalidateP2PTransaction(ctx context.Context, transactionID string) error {
+	return c.invalidate(ctx, "p2p:"+transactionID)
+}
+
+// InvalidateMassTransaction удаляет массовую транзакцию из кэша, например при получении callback'а.
+// Ответ запроса к API, начатого до вызова, в кэш не сохраняется.
+func (c *TransactionCache) InvalidateMassTransaction(ctx context.Context, transactionID string) error {
+	return c.invalidate(ctx, "mass:"+transactionID)
+}
+
+func (c *TransactionCache) invalidate(ctx context.Context, key string) error {
+	c.mu.Lock()
+	if c.fetching[key] > 0 {
+		c.generations[key]++
+	}
+	c.mu.Unlock()
+	return c.backend.Delete(ctx, key)
+}
+
+// startFetch регистрирует запрос к API по ключу и возвращает текущий номер инвалидации.
+func (c *TransactionCache) startFetch(key string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetching[key]++
+	return c.generations[key]
+}
+
+func (c *TransactionCache) finishFetch(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fetching[key]--; c.fetching[key] <= 0 {
+		delete(c.fetching, key)
+		delete(c.generations, key)
+	}
+}
+
+// invalidatedSince возвращает true, если ключ инвалидирован после startFetch, вернувшего generation.
+func (c *TransactionCache) invalidatedSince(key string, generation uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[key] != generation
+}
+
+func (c *TransactionCache) get(ctx context.Context, key string, out interface{}, fetch func(ctx context.Context) (interface{}, TransactionStateEnum, error)) error {
+	// ошибка кэша не должна ломать чтение, в этом случае идем в API
+	if data, ok, err := c.backend.Get(ctx, key); err == nil && ok {
+		if json.Unmarshal(data, out) == nil {
+			return nil
+		}
+	}
+
+	// запрос выполняется без отмены контекста вызывающих, чтобы отмена одного не ломала остальных,
+	// но не дольше fetchTimeout, а каждый вызывающий ждет результат не дольше своего ctx
+	data, err := c.group.do(ctx, key, func() ([]byte, error) {
+		generation := c.startFetch(key)
+		defer c.finishFetch(key)
+
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.fetchTimeout)
+		defer cancel()
+		resp, state, err := fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return nil, err
+		}
+		if ttl := c.ttl(state); ttl > 0 && !c.invalidatedSince(key, generation) {
+			_ = c.backend.Set(fetchCtx, key, data, ttl)
+			// Invalidate* мог выполниться между проверкой и Set
+			if c.invalidatedSince(key, generation) {
+				_ = c.backend.Delete(fetchCtx, key)
+			}
+		}
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// singleflightGroup объединяет одновременные вызовы с одинаковым ключом.
+type singleflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*singleflightCall
+}
+
+type singleflightCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// do выполняет fn в отдельной горутине один раз для всех одновременных вызовов с ключом key.
+// Вызывающий перестает ждать при отмене своего ctx, fn при этом продолжает выполняться для остальных.
+// Паника в fn возвращается всем ожидающим как ошибка.
+func (g *singleflightGroup) do(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*singleflightCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		call = &singleflightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(key, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.data, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *singleflightGroup) run(key string, call *singleflightCall, fn func() ([]byte, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.data, call.err = nil, fmt.Errorf("panic in transaction cache fetch: %v", r)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.data, call.err = fn()
+}
+
+// LRUCache - CacheBackend в памяти процесса с вытеснением давно не использованных записей.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRUCache создает LRU кэш на capacity записей.
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{capacity: capacity, items: make(map[string]*list.Element), order: list.New(), now: time.Now}
+}
+
+func (c *LRUCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*lruEntry)
+	if c.now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+func (c *LRUCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+func (c *LRUCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestTransactionCache tests the TransactionCache read-through and TTL policy
+func TestTransactionCache(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+
+	var final, pending bovasdk.P2PTransactionResponse
+	final.Payload.ID, final.Payload.State = "p2p-final", bovasdk.RepeatedAcceptedSuccessed
+	pending.Payload.ID, pending.Payload.State = "p2p-pending", bovasdk.WaitingPayment
+	server.AddP2PTransaction(final)
+	server.AddP2PTransaction(pending)
+
+	cache := bovasdk.NewTransactionCache(newTestSDK(t, server), bovasdk.NewLRUCache(10)).
+		WithTTLPolicy(func(state bovasdk.TransactionStateEnum) time.Duration {
+			if state == bovasdk.RepeatedAcceptedSuccessed {
+				return time.Hour
+			}
+			return 0
+		})
+
+	ctx := context.Background()
+	for _, id := range []string{"p2p-final", "p2p-pending"} {
+		if _, err := cache.GetP2PTransaction(ctx, id); err != nil {
+			t.Fatalf("GetP2PTransaction(%s) error = %v", id, err)
+		}
+	}
+	server.SetP2PState("p2p-final", bovasdk.RepeatedClosedFailed)
+	server.SetP2PState("p2p-pending", bovasdk.Paid)
+
+	resp, err := cache.GetP2PTransaction(ctx, "p2p-final")
+	if err != nil || resp.Payload.State != bovasdk.RepeatedAcceptedSuccessed {
+		t.Errorf("GetP2PTransaction(p2p-final) = %v, %v, want cached repeated_accepted_successed", resp, err)
+	}
+	resp, err = cache.GetP2PTransaction(ctx, "p2p-pending")
+	if err != nil || resp.Payload.State != bovasdk.Paid {
+		t.Errorf("GetP2PTransaction(p2p-pending) = %v, %v, want fresh paid", resp, err)
+	}
+
+	if err = cache.InvalidateP2PTransaction(ctx, "p2p-final"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = cache.GetP2PTransaction(ctx, "p2p-final")
+	if err != nil || resp.Payload.State != bovasdk.RepeatedClosedFailed {
+		t.Errorf("GetP2PTransaction(p2p-final) after invalidate = %v, %v, want repeated_closed_failed", resp, err)
+	}
+
+	if _, err = cache.GetP2PTransaction(ctx, "missing"); !bovasdk.IsNotFound(err) {
+		t.Errorf("GetP2PTransaction(missing) error = %v, want not found", err)
+	}
+
+	// по успешной транзакции может быть открыт диспут, поэтому она не кэшируется надолго
+	if ttl := bovasdk.DefaultCacheTTLPolicy(bovasdk.Successed); ttl >= time.Hour {
+		t.Errorf("DefaultCacheTTLPolicy(successed) = %v, want less than an hour", ttl)
+	}
+}
+
+// TestTransactionCacheWaiterContext tests that a caller waiting for a shared fetch returns on its own ctx cancellation
+func TestTransactionCacheWaiterContext(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	var tx bovasdk.P2PTransactionResponse
+	tx.Payload.ID, tx.Payload.State = "p2p-1", bovasdk.Paid
+	server.AddP2PTransaction(tx)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	block := func(next bovasdk.Handler) bovasdk.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-release
+			return next(req)
+		}
+	}
+	logger, err := bovasdk.NewLogger(false, "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk, err := bovasdk.NewBovaApiBuilder().ApiURL(server.URL).Secret(testSecret).Logger(logger).Middleware(block).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := bovasdk.NewTransactionCache(sdk, nil)
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := cache.GetP2PTransaction(context.Background(), "p2p-1")
+		first <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = cache.GetP2PTransaction(ctx, "p2p-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetP2PTransaction() waiter error = %v, want deadline exceeded", err)
+	}
+
+	close(release)
+	if err = <-first; err != nil {
+		t.Errorf("GetP2PTransaction() first caller error = %v", err)
+	}
+}
+
+// TestTransactionCacheInflightFetch tests that a hung fetch is bounded by the fetch timeout and that
+// an invalidation during a fetch is not overwritten by its response
+func TestTransactionCacheInflightFetch(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	var tx bovasdk.P2PTransactionResponse
+	tx.Payload.ID, tx.Payload.State = "p2p-1", bovasdk.Paid
+	server.AddP2PTransaction(tx)
+
+	var requests int32
+	hang := make(chan struct{})
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	block := func(next bovasdk.Handler) bovasdk.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&requests, 1)
+			select {
+			case <-hang:
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			default:
+			}
+			select {
+			case started <- struct{}{}:
+				<-release
+			default:
+			}
+			return next(req)
+		}
+	}
+	logger, err := bovasdk.NewLogger(false, "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk, err := bovasdk.NewBovaApiBuilder().ApiURL(server.URL).Secret(testSecret).Logger(logger).Middleware(block).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := bovasdk.NewTransactionCache(sdk, nil).WithFetchTimeout(50 * time.Millisecond)
+	ctx := context.Background()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := cache.GetP2PTransaction(ctx, "p2p-1")
+		first <- err
+	}()
+	<-started
+	if err = cache.InvalidateP2PTransaction(ctx, "p2p-1"); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err = <-first; err != nil {
+		t.Fatalf("GetP2PTransaction() error = %v", err)
+	}
+	if _, err = cache.GetP2PTransaction(ctx, "p2p-1"); err != nil || atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("GetP2PTransaction() after invalidation = %v, requests = %d, want a new request", err, requests)
+	}
+
+	close(hang)
+	if _, err = cache.GetMassTransaction(ctx, "mass-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetMassTransaction() with hung API error = %v, want deadline exceeded", err)
+	}
+}
+
+// TestLRUCache tests eviction and expiry of LRUCache
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	cache := bovasdk.NewLRUCache(2)
+
+	_ = cache.Set(ctx, "a", []byte("1"), time.Hour)
+	_ = cache.Set(ctx, "b", []byte("2"), time.Hour)
+	_, _, _ = cache.Get(ctx, "a")
+	_ = cache.Set(ctx, "c", []byte("3"), time.Hour)
+
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Error("Get(b) found, want evicted")
+	}
+	if v, ok, _ := cache.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Errorf("Get(a) = %q, %v, want 1", v, ok)
+	}
+
+	_ = cache.Set(ctx, "d", []byte("4"), -time.Second)
+	if _, ok, _ := cache.Get(ctx, "d"); ok {
+		t.Error("Get(d) found, want expired")
+	}
+}
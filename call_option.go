@@ -0,0 +1,99 @@
+package bovasdk
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ResponseMeta - метаданные ответа API, например для аудита или обращения в поддержку Bova.
+type ResponseMeta struct {
+	// StatusCode равен 0, если ответ не получен
+	StatusCode int
+	Header     http.Header
+	// Body - тело ответа без изменений, включая поля, неизвестные SDK
+	Body []byte
+	// Latency - время вызова вместе со всеми попытками
+	Latency time.Duration
+	// Attempts - число отправок запроса, больше 1 при повторах RetryMiddleware
+	Attempts int
+}
+
+// CallOption настраивает отдельный вызов API.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	meta                *ResponseMeta
+	recoverByMerchantID bool
+	// strictEnums переопределяет строгую проверку перечислений клиента, если задан
+	strictEnums *bool
+	// sbpBankValidation переопределяет проверку банка СБП клиента, если задан
+	sbpBankValidation *bool
+	// capabilityValidation переопределяет проверку по таблице возможностей клиента, если задан
+	capabilityValidation *bool
+}
+
+// WithResponseMeta заполняет meta метаданными ответа. meta заполняется и при ошибке вызова.
+func WithResponseMeta(meta *ResponseMeta) CallOption {
+	return func(o *callOptions) {
+		o.meta = meta
+	}
+}
+
+// WithStrictEnums включает или выключает для вызова строгую проверку перечислений: запрос с неизвестным
+// значением не отправляется, а ответ с неизвестным значением возвращает *UnknownEnumValueError.
+// По умолчанию используется настройка BovaApiBuilder.StrictEnums.
+func WithStrictEnums(strict bool) CallOption {
+	return func(o *callOptions) {
+		o.strictEnums = &strict
+	}
+}
+
+// WithSbpBankValidation включает или выключает для вызова проверку sbp_bank_name выплаты по справочнику банков СБП.
+// По умолчанию используется настройка BovaApiBuilder.ValidateSbpBanks, если справочник клиенту не задан,
+// используется SbpBanks.
+func WithSbpBankValidation(enabled bool) CallOption {
+	return func(o *callOptions) {
+		o.sbpBankValidation = &enabled
+	}
+}
+
+// WithCapabilityValidation включает или выключает для вызова проверку запроса по таблице возможностей,
+// см. BovaApiBuilder.ValidateCapabilities.
+func WithCapabilityValidation(enabled bool) CallOption {
+	return func(o *callOptions) {
+		o.capabilityValidation = &enabled
+	}
+}
+
+func newCallOptions(opts []CallOption) *callOptions {
+	options := &callOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+func (o *callOptions) setMeta(resp *http.Response, body []byte, latency time.Duration, attempts *int32) {
+	if o.meta == nil {
+		return
+	}
+	*o.meta = ResponseMeta{Body: body, Latency: latency, Attempts: int(atomic.LoadInt32(attempts))}
+	if resp != nil {
+		o.meta.StatusCode = resp.StatusCode
+		o.meta.Header = resp.Header
+	}
+}
+
+// attemptsKey - ключ контекста запроса со счетчиком отправок.
+type attemptsKey struct{}
+
+// countAttempts считает фактические отправки запроса, в том числе повторные.
+func countAttempts(next Handler) Handler {
+	return func(req *http.Request) (*http.Response, error) {
+		if counter, ok := req.Context().Value(attemptsKey{}).(*int32); ok {
+			atomic.AddInt32(counter, 1)
+		}
+		return next(req)
+	}
+}
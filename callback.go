@@ -0,0 +1,246 @@
+package bovasdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type CallbackEvent string
+
+const (
+	CallbackP2P     CallbackEvent = "p2p_transaction"
+	CallbackPayout  CallbackEvent = "mass_transaction"
+	CallbackDispute CallbackEvent = "p2p_dispute"
+
+	// defaultCallbackVersion используется, если в теле callback'а версия не указана.
+	defaultCallbackVersion = "v1"
+)
+
+// P2PCallback представляет callback об изменении p2p транзакции.
+type P2PCallback struct {
+	ID                string               `json:"id"`
+	MerchantID        string               `json:"merchant_id"`
+	Currency          CurrencyEnum         `json:"currency"`
+	State             TransactionStateEnum `json:"state"`
+	PaymentMethod     PaymentMethodEnum    `json:"payment_method"`
+	Rate              string               `json:"rate"`
+	Amount            string               `json:"amount"`
+	FiatAmount        string               `json:"fiat_amount"`
+	OldFiatAmount     string               `json:"old_fiat_amount"`
+	ServiceCommission string               `json:"service_commission"`
+	TotalAmount       string               `json:"total_amount"`
+	CreatedAt         string               `json:"created_at"`
+	UpdatedAt         string               `json:"updated_at"`
+	CloseAt           string               `json:"close_at"`
+	// Raw - исходный JSON объекта транзакции для аудита
+	Raw json.RawMessage `json:"-"`
+}
+
+// PayoutCallback представляет callback об изменении выплаты (массовой транзакции).
+type PayoutCallback struct {
+	ID                string               `json:"id"`
+	MerchantID        string               `json:"merchant_id"`
+	Currency          CurrencyEnum         `json:"currency"`
+	State             TransactionStateEnum `json:"state"`
+	PaymentMethod     PaymentMethodEnum    `json:"payment_method"`
+	Rate              string               `json:"rate"`
+	Amount            string               `json:"amount"`
+	FiatAmount        string               `json:"fiat_amount"`
+	OldFiatAmount     string               `json:"old_fiat_amount"`
+	CommissionType    string               `json:"commission_type"`
+	ServiceCommission string               `json:"service_commission"`
+	TotalAmount       string               `json:"total_amount"`
+	BankName          string               `json:"bank_name"`
+	SbpBankName       string               `json:"sbp_bank_name"`
+	RecipientCard     string               `json:"recipient_card"`
+	CreatedAt         string               `json:"created_at"`
+	UpdatedAt         string               `json:"updated_at"`
+	// Raw - исходный JSON объекта выплаты для аудита
+	Raw json.RawMessage `json:"-"`
+}
+
+// DisputeCallback представляет callback об изменении диспута.
+type DisputeCallback struct {
+	ID            int              `json:"id"`
+	State         DisputeStateEnum `json:"state"`
+	Repeated      bool             `json:"repeated"`
+	Amount        int              `json:"amount"`
+	TransactionID string           `json:"transaction_id"`
+	CreatedAt     string           `json:"created_at"`
+	UpdatedAt     string           `json:"updated_at"`
+	// Raw - исходный JSON объекта диспута для аудита
+	Raw json.RawMessage `json:"-"`
+}
+
+// Callback - результат декодирования тела callback'а. Заполнено ровно одно из полей P2P, Payout и Dispute.
+type Callback struct {
+	Event   CallbackEvent    `json:"event"`
+	Version string           `json:"version"`
+	P2P     *P2PCallback     `json:"p2p,omitempty"`
+	Payout  *PayoutCallback  `json:"payout,omitempty"`
+	Dispute *DisputeCallback `json:"dispute,omitempty"`
+	// Raw - полное исходное тело callback'а
+	Raw json.RawMessage `json:"-"`
+}
+
+// TransactionID возвращает ID транзакции, к которой относится событие.
+func (c *Callback) TransactionID() string {
+	switch {
+	case c.P2P != nil:
+		return c.P2P.ID
+	case c.Payout != nil:
+		return c.Payout.ID
+	case c.Dispute != nil:
+		return c.Dispute.TransactionID
+	default:
+		return ""
+	}
+}
+
+// callbackEnvelope - поля верхнего уровня, по которым определяется тип и версия события.
+type callbackEnvelope struct {
+	Event   CallbackEvent   `json:"event"`
+	Type    CallbackEvent   `json:"type"`
+	Version string          `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type callbackDecodeFunc func(event CallbackEvent, object json.RawMessage, cb *Callback) error
+
+// callbackDecoders - декодеры по версии формата callback'ов. Неизвестные версии декодируются
+// последним известным форматом, так как новые версии до сих пор только добавляли поля.
+var callbackDecoders = map[string]callbackDecodeFunc{
+	defaultCallbackVersion: decodeCallbackV1,
+}
+
+// DecodeCallback определяет тип события и декодирует тело callback'а.
+// Неизвестные поля игнорируются, исходный JSON сохраняется в поле Raw.
+func DecodeCallback(body []byte) (*Callback, error) {
+	var envelope callbackEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("error Unmarshal callback: %v", err)
+	}
+
+	object := json.RawMessage(body)
+	switch {
+	case isJSONObject(envelope.Payload):
+		object = envelope.Payload
+	case isJSONObject(envelope.Data):
+		object = envelope.Data
+	}
+
+	event := envelope.Event
+	if event == "" {
+		event = envelope.Type
+	}
+	if event == "" {
+		detected, err := detectCallbackEvent(object)
+		if err != nil {
+			return nil, err
+		}
+		event = detected
+	}
+
+	version := envelope.Version
+	if version == "" {
+		version = defaultCallbackVersion
+	}
+	decode, ok := callbackDecoders[version]
+	if !ok {
+		decode = callbackDecoders[defaultCallbackVersion]
+	}
+
+	cb := &Callback{Event: event, Version: version, Raw: append(json.RawMessage(nil), body...)}
+	if err := decode(event, object, cb); err != nil {
+		return nil, err
+	}
+	return cb, nil
+}
+
+func decodeCallbackV1(event CallbackEvent, object json.RawMessage, cb *Callback) error {
+	raw := append(json.RawMessage(nil), object...)
+
+	switch event {
+	case CallbackP2P:
+		cb.P2P = &P2PCallback{Raw: raw}
+		return unmarshalCallback(object, cb.P2P)
+	case CallbackPayout:
+		cb.Payout = &PayoutCallback{Raw: raw}
+		return unmarshalCallback(object, cb.Payout)
+	case CallbackDispute:
+		var dispute struct {
+			DisputeCallback
+			P2PTx struct {
+				ID string `json:"id"`
+			} `json:"p2p_tx"`
+		}
+		if err := unmarshalCallback(object, &dispute); err != nil {
+			return err
+		}
+		cb.Dispute = &dispute.DisputeCallback
+		if cb.Dispute.TransactionID == "" {
+			cb.Dispute.TransactionID = dispute.P2PTx.ID
+		}
+		cb.Dispute.Raw = raw
+		return nil
+	default:
+		return fmt.Errorf("unknown callback event: %s", event)
+	}
+}
+
+// detectCallbackEvent определяет тип события по характерным полям объекта.
+func detectCallbackEvent(object json.RawMessage) (CallbackEvent, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(object, &fields); err != nil {
+		return "", fmt.Errorf("error Unmarshal callback: %v", err)
+	}
+
+	has := func(keys ...string) bool {
+		for _, k := range keys {
+			if _, ok := fields[k]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("p2p_tx", "proof_image", "repeated"):
+		return CallbackDispute, nil
+	case has("form_url", "resipient_card", "payeer_identifier"):
+		return CallbackP2P, nil
+	case has("recipient_card", "commission_type", "sbp_bank_name"):
+		return CallbackPayout, nil
+	default:
+		return "", fmt.Errorf("cannot detect callback event")
+	}
+}
+
+func unmarshalCallback(object json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(object, v); err != nil {
+		return fmt.Errorf("error Unmarshal callback: %v", err)
+	}
+	return nil
+}
+
+func isJSONObject(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// CallbackFunc обрабатывает декодированный callback с уже проверенной подписью.
+type CallbackFunc func(ctx context.Context, cb *Callback) error
+
+// NewCallbackHandler создает http.Handler, который проверяет подпись и декодирует callback'и в типизированные DTO.
+func NewCallbackHandler(encoder *Encoder, handle CallbackFunc) *WebhookHandler {
+	return NewWebhookHandler(encoder, func(ctx context.Context, body []byte) error {
+		cb, err := DecodeCallback(body)
+		if err != nil {
+			return err
+		}
+		return handle(ctx, cb)
+	})
+}
@@ -0,0 +1,71 @@
+package bovasdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// TestDecodeCallbackGolden tests DecodeCallback against golden JSON fixtures in testdata/callbacks
+func TestDecodeCallbackGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "callbacks", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no callback fixtures found")
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			body, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cb, err := DecodeCallback(body)
+			if err != nil {
+				t.Fatalf("DecodeCallback() error = %v", err)
+			}
+			if !bytes.Equal(cb.Raw, body) {
+				t.Errorf("DecodeCallback() did not preserve raw body")
+			}
+
+			got, err := json.MarshalIndent(cb, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(fixture, ".json") + ".golden"
+			if *updateGolden {
+				if err = os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("error reading golden file, run with -update to create: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("DecodeCallback() = %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+// TestDecodeCallbackUnknown tests that an unrecognizable body is rejected
+func TestDecodeCallbackUnknown(t *testing.T) {
+	if _, err := DecodeCallback([]byte(`{"foo":"bar"}`)); err == nil {
+		t.Errorf("DecodeCallback() expected error for unknown event")
+	}
+	if _, err := DecodeCallback([]byte(`not json`)); err == nil {
+		t.Errorf("DecodeCallback() expected error for invalid json")
+	}
+}
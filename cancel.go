@@ -0,0 +1,98 @@
+package bovasdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// TransactionTerminalError возвращается при отмене транзакции, которая уже в финальном статусе.
+type TransactionTerminalError struct {
+	TransactionID string
+	State         TransactionStateEnum
+	// Err - исходная ошибка API
+	Err error
+}
+
+func (e *TransactionTerminalError) Error() string {
+	return fmt.Sprintf("transaction %s is already in terminal state %s", e.TransactionID, e.State)
+}
+
+func (e *TransactionTerminalError) Unwrap() error {
+	return e.Err
+}
+
+// CancelP2PTransaction отменяет ожидающую оплаты p2p транзакцию, например при уходе клиента с формы оплаты.
+// Для транзакции в финальном статусе возвращается *TransactionTerminalError.
+// Эндпоинт POST /v1/p2p_transactions/{id}/cancel не описан в документации Bova и является предположением,
+// если его нет, возвращается ошибка API.
+func (p2p *P2P) CancelP2PTransaction(ctx context.Context, transactionID string, opts ...CallOption) (*P2PTransactionResponse, error) {
+	resp, err := execute[P2PTransactionResponse](ctx, p2p.transport, apiCall{
+		method: http.MethodPost,
+		path:   "/v1/p2p_transactions/" + transactionID + "/cancel",
+	}, opts...)
+	if err != nil {
+		return nil, cancelError(ctx, transactionID, err, func(ctx context.Context) (TransactionStateEnum, error) {
+			tx, err := p2p.GetP2PTransaction(ctx, transactionID)
+			if err != nil {
+				return "", err
+			}
+			return tx.Payload.State, nil
+		})
+	}
+	return resp, nil
+}
+
+// CancelMassTransaction отменяет выплату, которая еще не отправлена получателю.
+// Для выплаты в финальном статусе возвращается *TransactionTerminalError.
+// Эндпоинт POST /v1/mass_transactions/{id}/cancel не описан в документации Bova и является предположением,
+// если его нет, возвращается ошибка API.
+func (mt *MassTransaction) CancelMassTransaction(ctx context.Context, transactionID string, opts ...CallOption) (*MassTransactionResponse, error) {
+	resp, err := execute[MassTransactionResponse](ctx, mt.transport, apiCall{
+		method: http.MethodPost,
+		path:   "/v1/mass_transactions/" + transactionID + "/cancel",
+	}, opts...)
+	if err != nil {
+		return nil, cancelError(ctx, transactionID, err, func(ctx context.Context) (TransactionStateEnum, error) {
+			tx, err := mt.GetMassTransaction(ctx, transactionID)
+			if err != nil {
+				return "", err
+			}
+			return TransactionStateEnum(tx.Payload.State), nil
+		})
+	}
+	return resp, nil
+}
+
+// cancelError превращает отказ API в *TransactionTerminalError, если транзакция в финальном статусе.
+// Статус берется из тела ответа, а если его там нет - запрашивается через getState. Коды 409 и 422
+// для отказа в отмене - предположение, как и сам эндпоинт.
+func cancelError(ctx context.Context, transactionID string, err error, getState func(ctx context.Context) (TransactionStateEnum, error)) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || (apiErr.StatusCode != http.StatusConflict && apiErr.StatusCode != http.StatusUnprocessableEntity) {
+		return err
+	}
+
+	var body struct {
+		Payload struct {
+			State TransactionStateEnum `json:"state"`
+		} `json:"payload"`
+	}
+	state := TransactionStateEnum("")
+	if json.Unmarshal(apiErr.Body, &body) == nil {
+		state = body.Payload.State
+	}
+	if state == "" {
+		var stateErr error
+		if state, stateErr = getState(ctx); stateErr != nil {
+			return err
+		}
+	}
+
+	if !state.IsTerminal() {
+		return err
+	}
+	return &TransactionTerminalError{TransactionID: transactionID, State: state, Err: err}
+}
@@ -0,0 +1,56 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestCancelTransaction tests CancelP2PTransaction and CancelMassTransaction against the mock server
+func TestCancelTransaction(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+	ctx := context.Background()
+
+	p2pReq := bovasdk.P2PTransactionRequest{UserUUID: "user", MerchantID: "order-1", Amount: 100, CallbackURL: "https://example.com", Currency: bovasdk.RUB, PaymentMethod: bovasdk.Card}
+	p2p, err := sdk.P2P.CreateP2PTransaction(ctx, p2pReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canceled, err := sdk.P2P.CancelP2PTransaction(ctx, p2p.Payload.ID)
+	if err != nil || canceled.Payload.State != bovasdk.Failed {
+		t.Fatalf("CancelP2PTransaction() = %+v, %v, want failed", canceled, err)
+	}
+
+	var terminal *bovasdk.TransactionTerminalError
+	_, err = sdk.P2P.CancelP2PTransaction(ctx, p2p.Payload.ID)
+	if !errors.As(err, &terminal) || terminal.State != bovasdk.Failed || terminal.TransactionID != p2p.Payload.ID {
+		t.Errorf("CancelP2PTransaction() repeated error = %v, want TransactionTerminalError", err)
+	}
+
+	req := *bovasdk.NewMassTransactionRequest("user", "payout-1", "4111111111111111", "https://example.com/callback", 500, bovasdk.RUB, bovasdk.Card)
+	payout, err := sdk.MassTransaction.CreateMassTransaction(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SetMassState(payout.Payload.ID, bovasdk.Paid)
+	_, err = sdk.MassTransaction.CancelMassTransaction(ctx, payout.Payload.ID)
+	var apiErr *bovasdk.APIError
+	if errors.As(err, &terminal) || !errors.As(err, &apiErr) {
+		t.Errorf("CancelMassTransaction() for paid error = %v, want plain APIError", err)
+	}
+
+	server.SetMassState(payout.Payload.ID, bovasdk.Successed)
+	_, err = sdk.MassTransaction.CancelMassTransaction(ctx, payout.Payload.ID)
+	if !errors.As(err, &terminal) || terminal.State != bovasdk.Successed || !errors.As(err, &apiErr) {
+		t.Errorf("CancelMassTransaction() for successed error = %v, want TransactionTerminalError", err)
+	}
+
+	if _, err = sdk.MassTransaction.CancelMassTransaction(ctx, "missing"); !bovasdk.IsNotFound(err) {
+		t.Errorf("CancelMassTransaction(missing) error = %v, want not found", err)
+	}
+}
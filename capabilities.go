@@ -0,0 +1,161 @@
+package bovasdk
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type Direction string
+
+const (
+	// DirectionDeposit - пополнение через P2PTransactionRequest.
+	DirectionDeposit Direction = "deposit"
+	// DirectionPayout - выплата через MassTransactionRequest.
+	DirectionPayout Direction = "payout"
+)
+
+// Capability описывает, что метод оплаты поддерживает для валюты в заданном направлении.
+// MinAmount и MaxAmount равные 0 означают отсутствие ограничения.
+type Capability struct {
+	Currency  CurrencyEnum      `json:"currency"`
+	Method    PaymentMethodEnum `json:"method"`
+	Direction Direction         `json:"direction"`
+	// RequiredFields - JSON имена необязательных в общем случае полей, без которых метод не работает
+	RequiredFields []string `json:"required_fields"`
+	MinAmount      int      `json:"min_amount"`
+	MaxAmount      int      `json:"max_amount"`
+}
+
+type capabilityKey struct {
+	currency  CurrencyEnum
+	method    PaymentMethodEnum
+	direction Direction
+}
+
+var capabilities = struct {
+	mu    sync.RWMutex
+	table map[capabilityKey]Capability
+}{table: make(map[capabilityKey]Capability)}
+
+// init заполняет таблицу по умолчанию. Публичной документации Bova по методам оплаты нет, поэтому таблица
+// составлена по полям запросов SDK и не является подтвержденной: в ней нет лимитов, а обязательными
+// отмечены только поля, без которых метод не имеет смысла. Условия договора задаются через RegisterCapability.
+func init() {
+	for _, c := range []Capability{
+		{Currency: RUB, Method: Card, Direction: DirectionDeposit},
+		{Currency: RUB, Method: SberPay, Direction: DirectionDeposit},
+		{Currency: RUB, Method: Sbp, Direction: DirectionDeposit},
+		{Currency: RUB, Method: SbpFast, Direction: DirectionDeposit},
+		{Currency: RUB, Method: Card, Direction: DirectionPayout},
+		{Currency: RUB, Method: Sbp, Direction: DirectionPayout, RequiredFields: []string{"sbp_bank_name"}},
+		{Currency: RUB, Method: SbpFast, Direction: DirectionPayout, RequiredFields: []string{"sbp_bank_name"}},
+		{Currency: UZS, Method: Card, Direction: DirectionDeposit},
+		{Currency: UZS, Method: Card, Direction: DirectionPayout},
+		{Currency: KRW, Method: AccountNumber, Direction: DirectionDeposit},
+		// реквизиты получателя для KRW (bank_name, recipient_*) не подтверждены, их проверяет API
+		{Currency: KRW, Method: AccountNumber, Direction: DirectionPayout},
+	} {
+		RegisterCapability(c)
+	}
+}
+
+// RegisterCapability добавляет или заменяет строку таблицы возможностей,
+// например чтобы задать лимиты из договора или включить новый метод до обновления SDK.
+func RegisterCapability(c Capability) {
+	capabilities.mu.Lock()
+	defer capabilities.mu.Unlock()
+	c.RequiredFields = append([]string(nil), c.RequiredFields...)
+	capabilities.table[capabilityKey{c.Currency, c.Method, c.Direction}] = c
+}
+
+// LookupCapability возвращает возможности метода оплаты для валюты и направления.
+func LookupCapability(currency CurrencyEnum, method PaymentMethodEnum, direction Direction) (Capability, bool) {
+	capabilities.mu.RLock()
+	defer capabilities.mu.RUnlock()
+	c, ok := capabilities.table[capabilityKey{currency, method, direction}]
+	if ok {
+		c.RequiredFields = append([]string(nil), c.RequiredFields...)
+	}
+	return c, ok
+}
+
+// SupportedMethods возвращает методы оплаты, доступные для валюты хотя бы в одном направлении.
+func SupportedMethods(currency CurrencyEnum) []PaymentMethodEnum {
+	return supportedMethods(currency, "")
+}
+
+// SupportedMethodsFor возвращает методы оплаты, доступные для валюты в заданном направлении.
+func SupportedMethodsFor(currency CurrencyEnum, direction Direction) []PaymentMethodEnum {
+	return supportedMethods(currency, direction)
+}
+
+func supportedMethods(currency CurrencyEnum, direction Direction) []PaymentMethodEnum {
+	capabilities.mu.RLock()
+	defer capabilities.mu.RUnlock()
+
+	seen := make(map[PaymentMethodEnum]struct{})
+	for key := range capabilities.table {
+		if key.currency == currency && (direction == "" || key.direction == direction) {
+			seen[key.method] = struct{}{}
+		}
+	}
+	return sortedMethods(seen)
+}
+
+// RequiredFields возвращает поля, обязательные для метода оплаты в заданном направлении в любой валюте.
+func RequiredFields(method PaymentMethodEnum, direction Direction) []string {
+	capabilities.mu.RLock()
+	defer capabilities.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	for key, c := range capabilities.table {
+		if key.method != method || key.direction != direction {
+			continue
+		}
+		for _, f := range c.RequiredFields {
+			seen[f] = struct{}{}
+		}
+	}
+
+	fields := make([]string, 0, len(seen))
+	for f := range seen {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// checkCapability проверяет запрос по таблице возможностей. Валюты, для которых в таблице
+// нет ни одной строки, не проверяются, чтобы зарегистрированные новые значения работали без изменения таблицы.
+func checkCapability(currency CurrencyEnum, method PaymentMethodEnum, direction Direction, amount int, hasField func(string) bool) error {
+	if len(supportedMethods(currency, "")) == 0 {
+		return nil
+	}
+
+	c, ok := LookupCapability(currency, method, direction)
+	if !ok {
+		return fmt.Errorf("payment method %s is not supported for %s %s", method, currency, direction)
+	}
+	for _, field := range c.RequiredFields {
+		if !hasField(field) {
+			return fmt.Errorf("%s is required for %s %s", field, method, direction)
+		}
+	}
+	if c.MinAmount > 0 && amount < c.MinAmount {
+		return fmt.Errorf("amount %d is less than minimum %d for %s %s", amount, c.MinAmount, currency, method)
+	}
+	if c.MaxAmount > 0 && amount > c.MaxAmount {
+		return fmt.Errorf("amount %d is greater than maximum %d for %s %s", amount, c.MaxAmount, currency, method)
+	}
+	return nil
+}
+
+func sortedMethods(set map[PaymentMethodEnum]struct{}) []PaymentMethodEnum {
+	methods := make([]PaymentMethodEnum, 0, len(set))
+	for m := range set {
+		methods = append(methods, m)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i] < methods[j] })
+	return methods
+}
@@ -0,0 +1,81 @@
+package bovasdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// TestCapabilityMatrix tests SupportedMethods, RequiredFields and request validation against the matrix
+func TestCapabilityMatrix(t *testing.T) {
+	if got := SupportedMethods(RUB); !reflect.DeepEqual(got, []PaymentMethodEnum{Card, SberPay, Sbp, SbpFast}) {
+		t.Errorf("SupportedMethods(RUB) = %v", got)
+	}
+	if got := SupportedMethodsFor(RUB, DirectionDeposit); !reflect.DeepEqual(got, []PaymentMethodEnum{Card, SberPay, Sbp, SbpFast}) {
+		t.Errorf("SupportedMethodsFor(RUB, deposit) = %v", got)
+	}
+	if got := SupportedMethodsFor(KRW, DirectionPayout); !reflect.DeepEqual(got, []PaymentMethodEnum{AccountNumber}) {
+		t.Errorf("SupportedMethodsFor(KRW, payout) = %v", got)
+	}
+	if got := RequiredFields(Sbp, DirectionPayout); !reflect.DeepEqual(got, []string{"sbp_bank_name"}) {
+		t.Errorf("RequiredFields(sbp, payout) = %v", got)
+	}
+
+	// таблица не подтверждена, поэтому Validate ее не использует
+	req := NewMassTransactionRequest(userUUID, "order-1", "79161234567", "https://example.com/callback", 1000, RUB, Sbp)
+	if err := req.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want matrix not checked", err)
+	}
+	if err := req.ValidateCapability(); err == nil {
+		t.Errorf("ValidateCapability() expected error for sbp payout without sbp_bank_name")
+	}
+	if err := req.WithSbpBankName("Сбербанк").ValidateCapability(); err != nil {
+		t.Errorf("ValidateCapability() error = %v", err)
+	}
+
+	req = NewMassTransactionRequest(userUUID, "order-2", "4111111111111111", "https://example.com/callback", 1000, UZS, SberPay)
+	if err := req.ValidateCapability(); err == nil {
+		t.Errorf("ValidateCapability() expected error for sberpay in UZS")
+	}
+
+	RegisterCapability(Capability{Currency: UZS, Method: Card, Direction: DirectionDeposit, MinAmount: 500})
+	defer RegisterCapability(Capability{Currency: UZS, Method: Card, Direction: DirectionDeposit})
+	deposit := NewP2PTransactionRequest(userUUID, "order-3", "payeer", "127.0.0.1", "trust", "https://example.com/callback", UZS, Card, 100)
+	if err := deposit.ValidateCapability(); err == nil {
+		t.Errorf("ValidateCapability() expected error for amount below minimum")
+	}
+}
+
+// TestCapabilityValidationOptIn tests that combinations missing from the matrix are sent by default
+// and rejected before sending only when the check is enabled
+func TestCapabilityValidationOptIn(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"result_code":"ok","payload":{"id":"mass-1"}}`))
+	}))
+	defer server.Close()
+
+	mt := massTransactionNew(newTransport(server.URL, NewEncoder("mock_api_secret"), server.Client()))
+	req := NewMassTransactionRequest(userUUID, "order-1", "4111111111111111", "https://example.com/callback", 1000, KRW, Card)
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() KRW card payout error = %v", err)
+	}
+	if _, err := mt.CreateMassTransaction(context.Background(), *req); err != nil || requests != 1 {
+		t.Errorf("CreateMassTransaction() = %v, requests = %d, want sent", err, requests)
+	}
+	if _, err := mt.CreateMassTransaction(context.Background(), *req, WithCapabilityValidation(true)); err == nil || requests != 1 {
+		t.Errorf("CreateMassTransaction() with capability validation = %v, requests = %d, want rejected", err, requests)
+	}
+
+	mt.transport.capabilities = true
+	if _, err := mt.CreateMassTransaction(context.Background(), *req); err == nil || requests != 1 {
+		t.Errorf("CreateMassTransaction() with client capability validation = %v, requests = %d, want rejected", err, requests)
+	}
+	if _, err := mt.CreateMassTransaction(context.Background(), *req, WithCapabilityValidation(false)); err != nil || requests != 2 {
+		t.Errorf("CreateMassTransaction() with validation disabled for the call = %v, requests = %d, want sent", err, requests)
+	}
+}
@@ -0,0 +1,261 @@
+// Package card содержит утилиты для номеров банковских карт: нормализацию, проверку по алгоритму Луна,
+// определение платежной системы по BIN и маскирование для интерфейсов и логов.
+//
+// Корейские локальные карты (например, внутренние карты BC Card) не поддерживаются: открытого источника с их
+// диапазонами BIN нет, поэтому во встроенной таблице их нет и DetectBrand возвращает для них Unknown или бренд
+// международной схемы. Диапазоны из договора с эквайером добавляются через RegisterBIN с собственным Brand.
+package card
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// MinLength и MaxLength - допустимая длина номера карты по ISO/IEC 7812.
+	MinLength = 12
+	MaxLength = 19
+)
+
+// Brand - платежная система карты.
+type Brand string
+
+const (
+	Unknown    Brand = ""
+	Mir        Brand = "mir"
+	Visa       Brand = "visa"
+	Mastercard Brand = "mastercard"
+	UzCard     Brand = "uzcard"
+	Humo       Brand = "humo"
+	UnionPay   Brand = "unionpay"
+	JCB        Brand = "jcb"
+	Amex       Brand = "amex"
+)
+
+var (
+	// ErrInvalidCharacters возвращается, если в номере есть что-то кроме цифр, пробелов и дефисов.
+	ErrInvalidCharacters = errors.New("card number contains invalid characters")
+	// ErrInvalidLength возвращается для номера короче MinLength или длиннее MaxLength цифр.
+	ErrInvalidLength = errors.New("card number has invalid length")
+	// ErrInvalidChecksum возвращается, если номер не проходит проверку по алгоритму Луна.
+	ErrInvalidChecksum = errors.New("card number has invalid checksum")
+)
+
+// Normalize убирает из номера пробелы и дефисы, например "4111 1111-1111 1111" -> "4111111111111111".
+func Normalize(number string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '\t', '\u00a0':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(number))
+}
+
+// Luhn проверяет контрольную цифру номера. Номер должен состоять только из цифр.
+func Luhn(number string) bool {
+	if number == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Validate проверяет номер карты после нормализации: только цифры, длина и контрольная цифра.
+func Validate(number string) error {
+	n := Normalize(number)
+	for i := 0; i < len(n); i++ {
+		if n[i] < '0' || n[i] > '9' {
+			return ErrInvalidCharacters
+		}
+	}
+	if len(n) < MinLength || len(n) > MaxLength {
+		return fmt.Errorf("%w: %d digits", ErrInvalidLength, len(n))
+	}
+	if !Luhn(n) {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// Mask оставляет первые 6 и последние 4 цифры номера, например "411111******1111".
+// Уже маскированный номер, например RecipientCard.Number, возвращается без изменений.
+func Mask(number string) string {
+	n := Normalize(number)
+	if strings.ContainsAny(n, "*•Xx") {
+		return n
+	}
+	if len(n) < MinLength {
+		return strings.Repeat("*", len(n))
+	}
+	return n[:6] + strings.Repeat("*", len(n)-10) + n[len(n)-4:]
+}
+
+// Last4 возвращает последние 4 цифры номера, например для "карта •••• 1111".
+func Last4(number string) string {
+	n := Normalize(number)
+	if len(n) < 4 {
+		return n
+	}
+	return n[len(n)-4:]
+}
+
+// cardFields - поля запросов и ответов API, в которых передается номер карты.
+const cardFields = `to_card|payeer_card_number|recipient_card|number`
+
+var (
+	// jsonCardField находит значение поля с номером карты в JSON, строкой или числом.
+	jsonCardField = regexp.MustCompile(`("(?:` + cardFields + `)"\s*:\s*)("(?:[^"\\]|\\.)*"|\d+)`)
+	// queryCardField находит значение параметра с номером карты в query строке или форме.
+	queryCardField = regexp.MustCompile(`((?:^|[?&])(?:` + cardFields + `)=)([^&#]*)`)
+	// panPattern находит последовательности от MinLength цифр, в том числе с разделителями.
+	panPattern = regexp.MustCompile(`\d(?:(?:[ -]|%20|\+)?\d){11,}`)
+)
+
+// Redact маскирует номера карт в значениях полей to_card, payeer_card_number, recipient_card и number
+// JSON тела или query строки, например перед записью в лог. В этих полях маскируются все последовательности
+// от MinLength цифр независимо от контрольной цифры, чтобы в лог не попали и номера с опечаткой.
+// Остальные поля не меняются.
+func Redact(text string) string {
+	for _, field := range []*regexp.Regexp{jsonCardField, queryCardField} {
+		text = field.ReplaceAllStringFunc(text, func(match string) string {
+			parts := field.FindStringSubmatch(match)
+			return parts[1] + panPattern.ReplaceAllStringFunc(parts[2], func(pan string) string {
+				return Mask(strings.NewReplacer("%20", "", "+", "").Replace(pan))
+			})
+		})
+	}
+	return text
+}
+
+//go:embed bins.csv
+var bundledBINs []byte
+
+type binRange struct {
+	from, to int
+	digits   int
+	brand    Brand
+}
+
+var bins = struct {
+	mu     sync.RWMutex
+	ranges []binRange
+}{}
+
+func init() {
+	scanner := bufio.NewScanner(bytes.NewReader(bundledBINs))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, ",")
+		if len(fields) != 3 {
+			panic(fmt.Sprintf("invalid bins.csv line %d: %q", line, text))
+		}
+		if err := RegisterBIN(fields[0], fields[1], Brand(fields[2])); err != nil {
+			panic(fmt.Sprintf("invalid bins.csv line %d: %v", line, err))
+		}
+	}
+}
+
+// RegisterBIN добавляет диапазон префиксов from-to одинаковой длины, например "2200", "2204" для Мир,
+// чтобы поддержать новые BIN до обновления SDK. Более длинный префикс имеет приоритет.
+func RegisterBIN(from, to string, brand Brand) error {
+	if len(from) != len(to) || len(from) == 0 || len(from) > 8 {
+		return fmt.Errorf("BIN range %s-%s must have equal length from 1 to 8 digits", from, to)
+	}
+	f, err := strconv.Atoi(from)
+	if err != nil {
+		return fmt.Errorf("invalid BIN %q: %v", from, err)
+	}
+	t, err := strconv.Atoi(to)
+	if err != nil {
+		return fmt.Errorf("invalid BIN %q: %v", to, err)
+	}
+	if f > t {
+		return fmt.Errorf("invalid BIN range %s-%s", from, to)
+	}
+
+	bins.mu.Lock()
+	defer bins.mu.Unlock()
+	bins.ranges = append(bins.ranges, binRange{from: f, to: t, digits: len(from), brand: brand})
+	sort.SliceStable(bins.ranges, func(i, j int) bool { return bins.ranges[i].digits > bins.ranges[j].digits })
+	return nil
+}
+
+// DetectBrand определяет платежную систему по BIN. Для неизвестного BIN возвращается Unknown.
+func DetectBrand(number string) Brand {
+	n := Normalize(number)
+	bins.mu.RLock()
+	defer bins.mu.RUnlock()
+	for _, r := range bins.ranges {
+		if len(n) < r.digits {
+			continue
+		}
+		prefix, err := strconv.Atoi(n[:r.digits])
+		if err != nil {
+			return Unknown
+		}
+		if prefix >= r.from && prefix <= r.to {
+			return r.brand
+		}
+	}
+	return Unknown
+}
+
+// ParseBrand приводит название платежной системы из ответа API, например RecipientCard.Brand "MasterCard"
+// или "МИР", к Brand. Для неизвестного названия возвращается Brand в нижнем регистре.
+func ParseBrand(name string) Brand {
+	key := strings.ToLower(strings.TrimSpace(name))
+	key = strings.NewReplacer(" ", "", "-", "", "_", "").Replace(key)
+	switch key {
+	case "mir", "мир":
+		return Mir
+	case "visa":
+		return Visa
+	case "mastercard", "mc", "maestro":
+		return Mastercard
+	case "uzcard":
+		return UzCard
+	case "humo", "хумо":
+		return Humo
+	case "unionpay", "cup":
+		return UnionPay
+	case "jcb":
+		return JCB
+	case "amex", "americanexpress":
+		return Amex
+	}
+	return Brand(key)
+}
+
+// MatchesBrand проверяет, что номер карты относится к платежной системе brand из ответа API.
+func MatchesBrand(number, brand string) bool {
+	detected := DetectBrand(number)
+	return detected != Unknown && detected == ParseBrand(brand)
+}
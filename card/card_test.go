@@ -0,0 +1,79 @@
+package card
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidate tests normalisation, Luhn check and length validation of card numbers
+func TestValidate(t *testing.T) {
+	for number, want := range map[string]error{
+		"4111111111111111":        nil,
+		"4111 1111-1111 1111":     nil,
+		"2200 2000 0000 0000":     nil,
+		"4111111111111112":        ErrInvalidChecksum,
+		"41111111":                ErrInvalidLength,
+		"4111 1111 1111 111a":     ErrInvalidCharacters,
+		"79161234567":             ErrInvalidLength,
+		"44111111111111111111111": ErrInvalidLength,
+	} {
+		if err := Validate(number); !errors.Is(err, want) {
+			t.Errorf("Validate(%q) = %v, want %v", number, err, want)
+		}
+	}
+}
+
+// TestDetectBrand tests BIN-range brand detection and API brand name parsing
+func TestDetectBrand(t *testing.T) {
+	for number, want := range map[string]Brand{
+		"2200200000000000": Mir,
+		"4111111111111111": Visa,
+		"5555555555554444": Mastercard,
+		"2221000000000009": Mastercard,
+		"8600123412341239": UzCard,
+		"9860123412341232": Humo,
+		"6200000000000005": UnionPay,
+		"3530111333300000": JCB,
+		"1234567890123452": Unknown,
+	} {
+		if got := DetectBrand(number); got != want {
+			t.Errorf("DetectBrand(%s) = %q, want %q", number, got, want)
+		}
+	}
+
+	if !MatchesBrand("5555 5555 5555 4444", "MasterCard") || !MatchesBrand("2200200000000000", "МИР") || MatchesBrand("4111111111111111", "mir") {
+		t.Error("MatchesBrand() mismatch")
+	}
+
+	if err := RegisterBIN("220070", "220070", "test_brand"); err != nil {
+		t.Fatal(err)
+	}
+	if got := DetectBrand("2200700000000000"); got != "test_brand" {
+		t.Errorf("DetectBrand() after RegisterBIN = %q, want longest prefix", got)
+	}
+}
+
+// TestMask tests masking and redaction of card numbers
+func TestMask(t *testing.T) {
+	if got := Mask("4111 1111 1111 1111"); got != "411111******1111" {
+		t.Errorf("Mask() = %q", got)
+	}
+	if got := Mask("411111******1111"); got != "411111******1111" {
+		t.Errorf("Mask() of masked number = %q", got)
+	}
+	if got := Last4("4111-1111-1111-1111"); got != "1111" {
+		t.Errorf("Last4() = %q", got)
+	}
+
+	// в полях карт маскируются и номера с неверной контрольной цифрой, остальные поля не меняются
+	for text, want := range map[string]string{
+		`{"to_card":"4111 1111 1111 1112","amount":100,"created_at":1760861234567,"id":"4111111111111111"}`: `{"to_card":"411111******1112","amount":100,"created_at":1760861234567,"id":"4111111111111111"}`,
+		`{"payeer_card_number": 5555555555554444, "recipient_card":"5555-5555-5555-4444"}`:                  `{"payeer_card_number": 555555******4444, "recipient_card":"555555******4444"}`,
+		`{"resipient_card":{"number":"220220******1234","bank_name":"Сбербанк"},"to_card":"79161234567"}`:   `{"resipient_card":{"number":"220220******1234","bank_name":"Сбербанк"},"to_card":"79161234567"}`,
+		`/v1/mass_transactions?to_card=4111+1111+1111+1111&page=1`:                                          `/v1/mass_transactions?to_card=411111******1111&page=1`,
+	} {
+		if got := Redact(text); got != want {
+			t.Errorf("Redact(%s) = %s, want %s", text, got, want)
+		}
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/AlexanderMikhel/bva/card"
 )
 
+// LoggingRoundTripper логирует запросы и ответы, номера карт в URL и телах маскируются.
 type LoggingRoundTripper struct {
 	log     Logger
 	proxied http.RoundTripper
@@ -24,7 +27,7 @@ func (lrt *LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, er
 	// Логирование запроса
 	if lrt.log.Enabled() {
 
-		lrt.log.Info(fmt.Sprintf("Request URL: %s", req.URL))
+		lrt.log.Info(fmt.Sprintf("Request URL: %s", card.Redact(req.URL.String())))
 		lrt.log.Info(fmt.Sprintf("Request Headers: %v", req.Header))
 
 		//пропускаем лог для загружаемых файлов и если тела нет впринципе
@@ -34,7 +37,7 @@ func (lrt *LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, er
 				return nil, err
 			}
 
-			lrt.log.Info(fmt.Sprintf("Request Body: %s", string(bodyBytes)))
+			lrt.log.Info(fmt.Sprintf("Request Body: %s", card.Redact(string(bodyBytes))))
 
 			req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		}
@@ -58,7 +61,7 @@ func (lrt *LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, er
 				return nil, err
 			}
 
-			lrt.log.Info(fmt.Sprintf("Response Body: %s", string(bodyBytes)))
+			lrt.log.Info(fmt.Sprintf("Response Body: %s", card.Redact(string(bodyBytes))))
 
 			// Восстановление тела ответа после чтения
 			resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
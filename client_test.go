@@ -0,0 +1,62 @@
+package bovasdk
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlexanderMikhel/bva/card"
+)
+
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Enabled() bool    { return true }
+func (l *recordingLogger) Debug(msg string) { l.messages = append(l.messages, msg) }
+func (l *recordingLogger) Info(msg string)  { l.messages = append(l.messages, msg) }
+func (l *recordingLogger) Warn(msg string)  { l.messages = append(l.messages, msg) }
+func (l *recordingLogger) Error(msg string) { l.messages = append(l.messages, msg) }
+
+// TestLoggingRoundTripperRedaction tests that card numbers are masked in logged requests and responses
+func TestLoggingRoundTripperRedaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"payload":{"recipient_card":"5555555555554444"}}`))
+	}))
+	defer server.Close()
+
+	log := &recordingLogger{}
+	client := &http.Client{Transport: NewLoggingRoundTripper(log, http.DefaultTransport)}
+	resp, err := client.Post(server.URL+"?to_card=4111111111111111", "application/json", strings.NewReader(`{"to_card":"4111 1111 1111 1111"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	all := strings.Join(log.messages, "\n")
+	if strings.Contains(all, "4111111111111111") || strings.Contains(all, "4111 1111 1111 1111") || strings.Contains(all, "5555555555554444") {
+		t.Errorf("log contains card number:\n%s", all)
+	}
+	if !strings.Contains(all, "411111******1111") || !strings.Contains(all, "555555******4444") {
+		t.Errorf("log does not contain masked card numbers:\n%s", all)
+	}
+}
+
+// TestValidateCardNumber tests card number validation of payout and deposit requests
+func TestValidateCardNumber(t *testing.T) {
+	req := NewMassTransactionRequest(userUUID, "order-1", "4111111111111112", "https://example.com/callback", 1000, RUB, Card)
+	if err := req.Validate(); !errors.Is(err, card.ErrInvalidChecksum) {
+		t.Errorf("Validate() error = %v, want invalid checksum", err)
+	}
+	req.ToCard = "2200 2000 0000 0000"
+	if err := req.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	p2p := NewP2PTransactionRequest(userUUID, "order-2", "payeer", "127.0.0.1", "trust", "https://example.com/callback", RUB, Card, 1000)
+	if err := p2p.WithPayeerCardNumber("1234").Validate(); !errors.Is(err, card.ErrInvalidLength) {
+		t.Errorf("Validate() error = %v, want invalid length", err)
+	}
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+)
+
+// commonFlags - флаги, общие для всех команд.
+type commonFlags struct {
+	config  string
+	output  string
+	verbose bool
+}
+
+func (a *app) newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet("bova "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	cf := &commonFlags{output: outputJSON}
+	fs.StringVar(&cf.config, "config", "", "путь к JSON файлу настроек (по умолчанию $"+envConfig+")")
+	// формат проверяется при разборе флагов, чтобы не выполнять запрос, результат которого не получится вывести
+	fs.Func("output", "формат вывода: json или table (по умолчанию json)", func(s string) error {
+		if err := checkOutputFormat(s); err != nil {
+			return err
+		}
+		cf.output = s
+		return nil
+	})
+	fs.BoolVar(&cf.verbose, "verbose", false, "логгировать запросы и ответы API")
+	return fs, cf
+}
+
+func (a *app) config(cf *commonFlags) (*config, error) {
+	cfg, err := loadConfig(cf.config, a.getenv)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("secret is required: set $%s or secret in config", envSecret)
+	}
+	return cfg, nil
+}
+
+func (a *app) sdk(cf *commonFlags) (*bovasdk.BovaApi, error) {
+	cfg, err := a.config(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := bovasdk.NewLogger(cf.verbose, "info")
+	if err != nil {
+		return nil, err
+	}
+
+	builder := bovasdk.NewBovaApiBuilder().
+		ApiURL(cfg.APIURL).
+		Secret(cfg.Secret).
+		Logger(logger)
+	if cfg.SecretEnvironment != "" {
+		env, ok := bovasdk.EnvironmentFrom(cfg.SecretEnvironment)
+		if !ok {
+			return nil, fmt.Errorf("unknown secret environment: %s", cfg.SecretEnvironment)
+		}
+		builder.SecretEnvironment(env)
+	}
+	if cfg.Environment != "" {
+		env, ok := bovasdk.EnvironmentFrom(cfg.Environment)
+		if !ok {
+			return nil, fmt.Errorf("unknown environment: %s", cfg.Environment)
+		}
+		if cfg.APIURL != "" {
+			env = env.WithURL(cfg.APIURL)
+		}
+		builder.Environment(env)
+	}
+	return builder.Build()
+}
+
+func (a *app) print(cf *commonFlags, v interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return printResult(a.stdout, cf.output, v)
+}
+
+func runP2PCreate(a *app, args []string) error {
+	fs, cf := a.newFlagSet("p2p create")
+	userUUID := fs.String("user-uuid", "", "UUID пользователя (обязательно)")
+	merchantID := fs.String("merchant-id", "", "ID заказа мерчанта (обязательно)")
+	payeerIdentifier := fs.String("payeer-identifier", "", "идентификатор плательщика (обязательно)")
+	payeerIP := fs.String("payeer-ip", "", "IP плательщика (обязательно)")
+	payeerType := fs.String("payeer-type", "", "тип плательщика (обязательно)")
+	callbackURL := fs.String("callback-url", "", "URL для callback'ов (обязательно)")
+	currency := fs.String("currency", string(bovasdk.RUB), "валюта")
+	method := fs.String("method", string(bovasdk.Card), "метод оплаты")
+	amount := fs.Int("amount", 0, "сумма (обязательно)")
+	email := fs.String("email", "", "email плательщика")
+	customerName := fs.String("customer-name", "", "имя плательщика")
+	redirectURL := fs.String("redirect-url", "", "URL для редиректа после оплаты")
+	payeerCard := fs.String("payeer-card", "", "номер карты плательщика")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cur, err := bovasdk.CurrencyFrom(*currency)
+	if err != nil {
+		return err
+	}
+	pm, err := bovasdk.PaymentMethodFrom(*method)
+	if err != nil {
+		return err
+	}
+
+	req := bovasdk.NewP2PTransactionRequest(*userUUID, *merchantID, *payeerIdentifier, *payeerIP, *payeerType, *callbackURL, cur, pm, *amount)
+	if *email != "" {
+		req.WithEmail(*email)
+	}
+	if *customerName != "" {
+		req.WithCustomerName(*customerName)
+	}
+	if *redirectURL != "" {
+		req.WithRedirectURL(*redirectURL)
+	}
+	if *payeerCard != "" {
+		req.WithPayeerCardNumber(*payeerCard)
+	}
+	if err = req.Validate(); err != nil {
+		return err
+	}
+
+	sdk, err := a.sdk(cf)
+	if err != nil {
+		return err
+	}
+	resp, err := sdk.P2P.CreateP2PTransaction(context.Background(), *req)
+	if err != nil {
+		return err
+	}
+	return a.print(cf, resp)
+}
+
+func runP2PGet(a *app, args []string) error {
+	fs, cf := a.newFlagSet("p2p get")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: bova p2p get [flags] <transaction_id>")
+	}
+
+	sdk, err := a.sdk(cf)
+	if err != nil {
+		return err
+	}
+	resp, err := sdk.P2P.GetP2PTransaction(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return a.print(cf, resp)
+}
+
+func runP2PCancel(a *app, args []string) error {
+	fs, cf := a.newFlagSet("p2p cancel")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: bova p2p cancel [flags] <transaction_id>")
+	}
+
+	sdk, err := a.sdk(cf)
+	if err != nil {
+		return err
+	}
+	resp, err := sdk.P2P.CancelP2PTransaction(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return a.print(cf, resp)
+}
+
+func runPayoutCreate(a *app, args []string) error {
+	fs, cf := a.newFlagSet("payout create")
+	userUUID := fs.String("user-uuid", "", "UUID пользователя (обязательно)")
+	merchantID := fs.String("merchant-id", "", "ID выплаты мерчанта (обязательно)")
+	toCard := fs.String("to-card", "", "номер карты или телефона получателя (обязательно)")
+	callbackURL := fs.String("callback-url", "", "URL для callback'ов (обязательно)")
+	currency := fs.String("currency", string(bovasdk.RUB), "валюта")
+	method := fs.String("method", string(bovasdk.Card), "метод выплаты")
+	amount := fs.Int("amount", 0, "сумма (обязательно)")
+	bankName := fs.String("bank-name", "", "название банка")
+	sbpBankName := fs.String("sbp-bank-name", "", "название банка СБП")
+	firstName := fs.String("first-name", "", "имя получателя")
+	lastName := fs.String("last-name", "", "фамилия получателя")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cur, err := bovasdk.CurrencyFrom(*currency)
+	if err != nil {
+		return err
+	}
+	pm, err := bovasdk.PaymentMethodFrom(*method)
+	if err != nil {
+		return err
+	}
+
+	req := bovasdk.NewMassTransactionRequest(*userUUID, *merchantID, *toCard, *callbackURL, *amount, cur, pm)
+	if *bankName != "" {
+		req.WithBankName(*bankName)
+	}
+	if *sbpBankName != "" {
+		req.WithSbpBankName(*sbpBankName)
+	}
+	if *firstName != "" {
+		req.WithRecipientFirstName(*firstName)
+	}
+	if *lastName != "" {
+		req.WithRecipientLastName(*lastName)
+	}
+	if err = req.Validate(); err != nil {
+		return err
+	}
+
+	sdk, err := a.sdk(cf)
+	if err != nil {
+		return err
+	}
+	resp, err := sdk.MassTransaction.CreateMassTransaction(context.Background(), *req)
+	if err != nil {
+		return err
+	}
+	return a.print(cf, resp)
+}
+
+func runPayoutGet(a *app, args []string) error {
+	fs, cf := a.newFlagSet("payout get")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: bova payout get [flags] <transaction_id>")
+	}
+
+	sdk, err := a.sdk(cf)
+	if err != nil {
+		return err
+	}
+	resp, err := sdk.MassTransaction.GetMassTransaction(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return a.print(cf, resp)
+}
+
+func runPayoutCancel(a *app, args []string) error {
+	fs, cf := a.newFlagSet("payout cancel")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: bova payout cancel [flags] <transaction_id>")
+	}
+
+	sdk, err := a.sdk(cf)
+	if err != nil {
+		return err
+	}
+	resp, err := sdk.MassTransaction.CancelMassTransaction(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return a.print(cf, resp)
+}
+
+func runDisputeCreate(a *app, args []string) error {
+	fs, cf := a.newFlagSet("dispute create")
+	transactionID := fs.String("transaction-id", "", "ID p2p транзакции (обязательно)")
+	amount := fs.Int("amount", 0, "сумма диспута (обязательно)")
+	image := fs.String("image", "", "файл с подтверждением оплаты (обязательно)")
+	image2 := fs.String("image2", "", "второй файл с подтверждением оплаты")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *transactionID == "" || *image == "" {
+		return errors.New("-transaction-id and -image are required")
+	}
+
+	img, err := bovasdk.NewProofImageFromPath(*image)
+	if err != nil {
+		return err
+	}
+	req := bovasdk.NewP2PDisputeRequestWithImage(*transactionID, *amount, img)
+	if *image2 != "" {
+		img2, err := bovasdk.NewProofImageFromPath(*image2)
+		if err != nil {
+			return err
+		}
+		req.WithProofImage2Image(img2)
+	}
+
+	sdk, err := a.sdk(cf)
+	if err != nil {
+		return err
+	}
+	resp, err := sdk.P2P.CreateP2PDispute(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	return a.print(cf, resp)
+}
+
+// bodyFlags добавляет флаги для чтения тела из аргумента, файла или stdin.
+func bodyFlags(fs *flag.FlagSet) func(a *app) ([]byte, error) {
+	body := fs.String("body", "", "тело запроса")
+	file := fs.String("file", "", "файл с телом запроса (по умолчанию stdin)")
+	return func(a *app) ([]byte, error) {
+		switch {
+		case *body != "":
+			return []byte(*body), nil
+		case *file != "":
+			return os.ReadFile(*file)
+		default:
+			return io.ReadAll(a.stdin)
+		}
+	}
+}
+
+func runSign(a *app, args []string) error {
+	fs, cf := a.newFlagSet("sign")
+	readBody := bodyFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := a.config(cf)
+	if err != nil {
+		return err
+	}
+	body, err := readBody(a)
+	if err != nil {
+		return err
+	}
+
+	return a.print(cf, map[string]string{"signature": bovasdk.NewEncoder(cfg.Secret).CalculateSignature(body)})
+}
+
+func runVerifySignature(a *app, args []string) error {
+	fs, cf := a.newFlagSet("verify-signature")
+	readBody := bodyFlags(fs)
+	signature := fs.String("signature", "", "проверяемая подпись (обязательно)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *signature == "" {
+		return errors.New("-signature is required")
+	}
+
+	cfg, err := a.config(cf)
+	if err != nil {
+		return err
+	}
+	body, err := readBody(a)
+	if err != nil {
+		return err
+	}
+
+	valid := bovasdk.NewEncoder(cfg.Secret).VerifySignature(body, *signature)
+	if err = a.print(cf, map[string]bool{"valid": valid}); err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("signature is invalid")
+	}
+	return nil
+}
+
+func runWebhookListen(a *app, args []string) error {
+	fs, cf := a.newFlagSet("webhook listen")
+	addr := fs.String("addr", ":8080", "адрес для приема callback'ов")
+	path := fs.String("path", "/callback", "путь для приема callback'ов")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := a.config(cf)
+	if err != nil {
+		return err
+	}
+
+	handler := bovasdk.NewWebhookHandler(bovasdk.NewEncoder(cfg.Secret), func(ctx context.Context, body []byte) error {
+		result := map[string]interface{}{"received_at": time.Now().Format(time.RFC3339)}
+		if cb, err := bovasdk.DecodeCallback(body); err == nil {
+			result["event"] = cb.Event
+			result["callback"] = cb
+		} else {
+			var raw interface{}
+			if err = json.Unmarshal(body, &raw); err != nil {
+				raw = string(body)
+			}
+			result["raw"] = raw
+		}
+		return a.print(cf, result)
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(*path, handler)
+	server := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(a.stderr, "listening on %s%s\n", *addr, *path)
+	if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
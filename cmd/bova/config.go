@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const (
+	envAPIURL = "BOVA_API_URL"
+	envSecret = "BOVA_SECRET"
+	envConfig = "BOVA_CONFIG"
+	// envSecretEnv - окружение, для которого выпущен ключ: sandbox или production
+	envSecretEnv = "BOVA_SECRET_ENV"
+	// envEnvironment - окружение API: sandbox или production, для прокси задается вместе с BOVA_API_URL
+	envEnvironment = "BOVA_ENV"
+)
+
+// config содержит настройки подключения к API.
+type config struct {
+	APIURL            string `json:"api_url"`
+	Secret            string `json:"secret"`
+	SecretEnvironment string `json:"secret_environment"`
+	Environment       string `json:"environment"`
+}
+
+// loadConfig читает настройки из JSON файла (флаг -config или BOVA_CONFIG),
+// значения из переменных окружения имеют приоритет над файлом.
+func loadConfig(path string, getenv func(string) string) (*config, error) {
+	cfg := &config{}
+
+	if path == "" {
+		path = getenv(envConfig)
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config: %v", err)
+		}
+		if err = json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("error parsing config %s: %v", path, err)
+		}
+	}
+
+	if v := getenv(envAPIURL); v != "" {
+		cfg.APIURL = v
+	}
+	if v := getenv(envSecret); v != "" {
+		cfg.Secret = v
+	}
+	if v := getenv(envSecretEnv); v != "" {
+		cfg.SecretEnvironment = v
+	}
+	if v := getenv(envEnvironment); v != "" {
+		cfg.Environment = v
+	}
+
+	return cfg, nil
+}
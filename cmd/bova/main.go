@@ -0,0 +1,85 @@
+// Команда bova - консольная утилита поддержки для работы с Bova API.
+//
+// Использование:
+//
+//	bova p2p create|get
+//	bova payout create|get
+//	bova dispute create --image file.png
+//	bova sign | verify-signature
+//	bova webhook listen
+//
+// Настройки подключения берутся из переменных окружения BOVA_API_URL и BOVA_SECRET
+// или из JSON файла, заданного флагом -config или переменной BOVA_CONFIG.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// app содержит окружение, в котором выполняются команды.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	// mu защищает вывод от перемешивания при параллельных callback'ах
+	mu sync.Mutex
+}
+
+// command описывает подкоманду утилиты.
+type command struct {
+	path  string
+	usage string
+	run   func(a *app, args []string) error
+}
+
+var commands = []command{
+	{"p2p create", "создать p2p транзакцию", runP2PCreate},
+	{"p2p get", "получить p2p транзакцию по ID", runP2PGet},
+	{"p2p cancel", "отменить p2p транзакцию, ожидающую оплаты", runP2PCancel},
+	{"payout create", "создать выплату", runPayoutCreate},
+	{"payout get", "получить выплату по ID", runPayoutGet},
+	{"payout cancel", "отменить выплату", runPayoutCancel},
+	{"dispute create", "создать диспут по p2p транзакции", runDisputeCreate},
+	{"sign", "вычислить подпись тела запроса", runSign},
+	{"verify-signature", "проверить подпись тела запроса", runVerifySignature},
+	{"webhook listen", "принимать callback'и и печатать проверенные события", runWebhookListen},
+}
+
+func main() {
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	os.Exit(a.run(os.Args[1:]))
+}
+
+func (a *app) run(args []string) int {
+	for _, cmd := range commands {
+		parts := strings.Fields(cmd.path)
+		if len(args) < len(parts) || strings.Join(args[:len(parts)], " ") != cmd.path {
+			continue
+		}
+		if err := cmd.run(a, args[len(parts):]); err != nil {
+			fmt.Fprintf(a.stderr, "bova %s: %v\n", cmd.path, err)
+			return 1
+		}
+		return 0
+	}
+
+	a.usage()
+	return 2
+}
+
+func (a *app) usage() {
+	fmt.Fprintln(a.stderr, "Использование: bova <команда> [флаги]")
+	fmt.Fprintln(a.stderr, "")
+	fmt.Fprintln(a.stderr, "Команды:")
+	for _, cmd := range commands {
+		fmt.Fprintf(a.stderr, "  %-18s %s\n", cmd.path, cmd.usage)
+	}
+	fmt.Fprintln(a.stderr, "")
+	fmt.Fprintln(a.stderr, "Флаги команды: bova <команда> -h")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+func newTestApp(env map[string]string) (*app, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	return &app{
+		stdin:  strings.NewReader(""),
+		stdout: stdout,
+		stderr: &bytes.Buffer{},
+		getenv: func(key string) string { return env[key] },
+	}, stdout
+}
+
+// TestSignAndVerify tests the sign and verify-signature commands
+func TestSignAndVerify(t *testing.T) {
+	body := `{"amount":300}`
+	signature := bovasdk.NewEncoder("secret").CalculateSignature([]byte(body))
+
+	a, stdout := newTestApp(map[string]string{envSecret: "secret"})
+	if code := a.run([]string{"sign", "-body", body}); code != 0 {
+		t.Fatalf("sign exit code = %d", code)
+	}
+	if !strings.Contains(stdout.String(), signature) {
+		t.Errorf("sign output = %s, want signature %s", stdout.String(), signature)
+	}
+
+	a, _ = newTestApp(map[string]string{envSecret: "secret"})
+	if code := a.run([]string{"verify-signature", "-body", body, "-signature", signature}); code != 0 {
+		t.Errorf("verify-signature exit code = %d, want 0", code)
+	}
+	a, _ = newTestApp(map[string]string{envSecret: "secret"})
+	if code := a.run([]string{"verify-signature", "-body", body, "-signature", "invalid"}); code != 1 {
+		t.Errorf("verify-signature exit code = %d, want 1", code)
+	}
+}
+
+// TestP2PGetTable tests the p2p get command with config file and table output
+func TestP2PGetTable(t *testing.T) {
+	server := bovatest.NewServer("secret")
+	defer server.Close()
+
+	var tx bovasdk.P2PTransactionResponse
+	tx.ResultCode = "ok"
+	tx.Payload.ID = "p2p-1"
+	tx.Payload.State = bovasdk.Paid
+	server.AddP2PTransaction(tx)
+
+	configPath := filepath.Join(t.TempDir(), "bova.json")
+	if err := os.WriteFile(configPath, []byte(`{"api_url":"`+server.URL+`","secret":"secret"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, stdout := newTestApp(nil)
+	if code := a.run([]string{"p2p", "get", "-config", configPath, "-output", "table", "p2p-1"}); code != 0 {
+		t.Fatalf("p2p get exit code = %d, stderr: %s", code, a.stderr)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "payload.state") || !strings.Contains(out, "paid") {
+		t.Errorf("p2p get output = %s", out)
+	}
+}
+
+// TestUnknownOutputFormat tests that an unknown output format is rejected before the payout is created
+func TestUnknownOutputFormat(t *testing.T) {
+	server := bovatest.NewServer("secret")
+	defer server.Close()
+
+	a, _ := newTestApp(map[string]string{envAPIURL: server.URL, envSecret: "secret"})
+	code := a.run([]string{"payout", "create", "-output", "yaml", "-user-uuid", "user", "-merchant-id", "order-1",
+		"-to-card", "4111111111111111", "-callback-url", "https://example.com/callback", "-amount", "200"})
+	if code == 0 {
+		t.Fatalf("payout create exit code = 0, want error")
+	}
+
+	resp, err := http.Get(server.URL + "/v1/mass_transactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var list bovasdk.MassTransactionListResponse
+	if err = json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Payload) != 0 {
+		t.Errorf("payout create with unknown output format created %d payouts", len(list.Payload))
+	}
+}
+
+// TestP2PCreateValidation tests that an incomplete p2p transaction is rejected before the API is called
+func TestP2PCreateValidation(t *testing.T) {
+	server := bovatest.NewServer("secret")
+	defer server.Close()
+
+	a, _ := newTestApp(map[string]string{envAPIURL: server.URL, envSecret: "secret"})
+	code := a.run([]string{"p2p", "create", "-user-uuid", "user", "-merchant-id", "order-1",
+		"-callback-url", "https://example.com/callback", "-amount", "200"})
+	if code == 0 {
+		t.Fatalf("p2p create without payeer fields exit code = 0, want error")
+	}
+
+	resp, err := http.Get(server.URL + "/v1/p2p_transactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var list bovasdk.P2PTransactionListResponse
+	if err = json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Payload) != 0 {
+		t.Errorf("p2p create without payeer fields created %d transactions", len(list.Payload))
+	}
+}
+
+// TestLoadConfigEnvOverridesFile tests that env values take priority over the config file
+func TestLoadConfigEnvOverridesFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "bova.json")
+	if err := os.WriteFile(configPath, []byte(`{"api_url":"https://file","secret":"file"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig("", func(key string) string {
+		return map[string]string{envConfig: configPath, envSecret: "env"}[key]
+	})
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.APIURL != "https://file" || cfg.Secret != "env" {
+		t.Errorf("loadConfig() = %+v", cfg)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// checkOutputFormat проверяет, что формат вывода поддерживается.
+func checkOutputFormat(format string) error {
+	switch format {
+	case outputJSON, outputTable:
+		return nil
+	}
+	return fmt.Errorf("unknown output format: %s", format)
+}
+
+// printResult выводит значение в формате JSON или в виде таблицы "поле - значение".
+func printResult(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputTable:
+		rows, err := flatten(v)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, row := range rows {
+			fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1])
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+}
+
+// flatten превращает значение в отсортированный список пар "путь.к.полю" - значение.
+func flatten(v interface{}) ([][2]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err = json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	var rows [][2]string
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			for k, child := range val {
+				walk(joinKey(prefix, k), child)
+			}
+		case []interface{}:
+			for i, child := range val {
+				walk(joinKey(prefix, fmt.Sprint(i)), child)
+			}
+		case nil:
+			rows = append(rows, [2]string{prefix, ""})
+		case string:
+			rows = append(rows, [2]string{prefix, val})
+		default:
+			rows = append(rows, [2]string{prefix, fmt.Sprint(val)})
+		}
+	}
+	walk("", generic)
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	return rows, nil
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return strings.Join([]string{prefix, key}, ".")
+}
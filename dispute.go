@@ -0,0 +1,103 @@
+package bovasdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// P2PDisputeFilter задает фильтры для списка диспутов. Пустые поля не фильтруют.
+// Параметры запроса не описаны в документации Bova и являются предположением.
+type P2PDisputeFilter struct {
+	TransactionID string
+	State         DisputeStateEnum
+	Repeated      *bool
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	Page          int
+	PerPage       int
+}
+
+func (f P2PDisputeFilter) query() url.Values {
+	q := url.Values{}
+	if f.TransactionID != "" {
+		q.Set(transactionIdForm, f.TransactionID)
+	}
+	if f.State != "" {
+		q.Set("state", string(f.State))
+	}
+	if f.Repeated != nil {
+		q.Set("repeated", strconv.FormatBool(*f.Repeated))
+	}
+	if !f.CreatedFrom.IsZero() {
+		q.Set("created_from", f.CreatedFrom.Format(time.RFC3339))
+	}
+	if !f.CreatedTo.IsZero() {
+		q.Set("created_to", f.CreatedTo.Format(time.RFC3339))
+	}
+	if f.Page > 0 {
+		q.Set("page", strconv.Itoa(f.Page))
+	}
+	if f.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(f.PerPage))
+	}
+	return q
+}
+
+// GetP2PDispute получает информацию о диспуте по его ID.
+// Эндпоинт GET /v1/p2p_disputes/{id} не описан в документации Bova и может отсутствовать, тогда возвращается ошибка API.
+func (p2p *P2P) GetP2PDispute(ctx context.Context, disputeID int, opts ...CallOption) (*P2PDisputeResponse, error) {
+	return execute[P2PDisputeResponse](ctx, p2p.transport, apiCall{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/v1/p2p_disputes/%d", disputeID),
+	}, opts...)
+}
+
+// ListP2PDisputes получает список диспутов, например все диспуты по одной транзакции.
+// Эндпоинт GET /v1/p2p_disputes не описан в документации Bova и может отсутствовать, тогда возвращается ошибка API.
+func (p2p *P2P) ListP2PDisputes(ctx context.Context, filter P2PDisputeFilter, opts ...CallOption) (*P2PDisputeListResponse, error) {
+	return execute[P2PDisputeListResponse](ctx, p2p.transport, apiCall{
+		method: http.MethodGet,
+		path:   "/v1/p2p_disputes",
+		query:  filter.query(),
+	}, opts...)
+}
+
+// defaultDisputePollInterval - интервал опроса диспута, если WaitP2PDispute передан interval <= 0
+const defaultDisputePollInterval = 5 * time.Second
+
+// WaitP2PDispute опрашивает диспут с заданным интервалом, пока он не перейдет в финальный статус.
+// При interval <= 0 используется интервал 5 секунд. Временные ошибки (429, 5xx, сбой соединения)
+// не прерывают ожидание, оно прерывается при отмене ctx или при остальных ошибках запроса.
+func (p2p *P2P) WaitP2PDispute(ctx context.Context, disputeID int, interval time.Duration) (*P2PDisputeResponse, error) {
+	if interval <= 0 {
+		interval = defaultDisputePollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *P2PDisputeResponse
+	for {
+		resp, err := p2p.GetP2PDispute(ctx, disputeID)
+		switch {
+		case err == nil:
+			last = resp
+		case isRetryable(err) && ctx.Err() == nil:
+			// следующая попытка будет на следующем тике
+		default:
+			return nil, err
+		}
+		if err == nil && resp.Data.IsResolved() {
+			return resp, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package bovasdk
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// newDisputeBody формирует multipart тело запроса на диспут.
+func newDisputeBody(req *P2PDisputeRequest) *multipartBody {
+	form := NewMultipartForm().
+		WithField(transactionIdForm, req.TransactionID).
+		WithField(p2pDisputeAmountForm, strconv.Itoa(req.Amount)).
+		WithProofImage(p2pDisputeProofImageForm, &req.ProofImage)
+	if req.ProofImage2 != nil {
+		form.WithProofImage(p2pDisputeProofImageForm2, req.ProofImage2)
+	}
+	return newMultipartBody(form)
+}
+
+// writeProofImage записывает изображение в multipart форму с указанием типа содержимого.
+// Файл из NewP2PDisputeRequest закрывается и при ошибке.
+func writeProofImage(writer *multipart.Writer, field string, img *ProofImage) error {
+	content, release, err := img.open()
+	if err != nil {
+		if img.closer != nil {
+			_ = img.closer.Close()
+		}
+		return err
+	}
+	defer release()
+
+	part, err := writer.CreatePart(proofImageHeader(field, img))
+	if err != nil {
+		return fmt.Errorf("error creating form File with name: %s, err: %v", img.Name, err)
+	}
+	if _, err = io.Copy(part, content); err != nil {
+		return fmt.Errorf("error copying File %s: %w", img.Name, err)
+	}
+	return nil
+}
+
+func proofImageHeader(field string, img *ProofImage) textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(field), quoteEscaper.Replace(filepath.Base(img.Name))))
+	header.Set("Content-Type", img.ContentType)
+	return header
+}
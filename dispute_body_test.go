@@ -0,0 +1,180 @@
+package bovasdk
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDisputeBodyContentLength tests that the precomputed length matches the streamed body
+func TestDisputeBodyContentLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proof.png")
+	if err := os.WriteFile(path, append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, 4096)...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fromPath, err := NewProofImageFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromBytes, err := NewProofImageFromBytes("second \"proof\".png", pngHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := NewP2PDisputeRequestWithImage("tx-1", 100, fromPath)
+	req.WithProofImage2Image(fromBytes)
+	body := newDisputeBody(&req)
+
+	streamed, err := io.ReadAll(body.reader())
+	if err != nil {
+		t.Fatalf("reading streamed body error = %v", err)
+	}
+	if length := body.contentLength(); length != int64(len(streamed)) {
+		t.Errorf("contentLength() = %d, streamed %d bytes", length, len(streamed))
+	}
+	if !body.replayable() {
+		t.Errorf("replayable() = false, want true for path and bytes images")
+	}
+
+	fromReader, err := NewProofImageFromReader("proof.png", io.MultiReader(bytes.NewReader(pngHeader)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = NewP2PDisputeRequestWithImage("tx-1", 100, fromReader)
+	body = newDisputeBody(&req)
+	if body.contentLength() != -1 || body.replayable() {
+		t.Errorf("non-seekable reader image body length = %d, replayable = %v, want -1 and false", body.contentLength(), body.replayable())
+	}
+}
+
+// TestCreateP2PDisputeStreaming tests the request sent with known and unknown content length
+func TestCreateP2PDisputeStreaming(t *testing.T) {
+	var gotLength int64
+	var gotChunked bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLength = r.ContentLength
+		gotChunked = len(r.TransferEncoding) > 0 && r.TransferEncoding[0] == "chunked"
+		if _, _, err := r.FormFile(p2pDisputeProofImageForm); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","data":{"id":1,"state":"opened"}}`))
+	}))
+	defer server.Close()
+	p2p := p2pNew(newTransport(server.URL, NewEncoder("mock_api_secret"), server.Client()))
+
+	fromBytes, err := NewProofImageFromBytes("proof.png", pngHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p2p.CreateP2PDispute(context.Background(), NewP2PDisputeRequestWithImage("tx-1", 100, fromBytes)); err != nil {
+		t.Fatalf("CreateP2PDispute() error = %v", err)
+	}
+	if gotLength <= int64(len(pngHeader)) || gotChunked {
+		t.Errorf("CreateP2PDispute() content length = %d, chunked = %v, want known length", gotLength, gotChunked)
+	}
+
+	fromReader, err := NewProofImageFromReader("proof.png", bytes.NewReader(pngHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p2p.CreateP2PDispute(context.Background(), NewP2PDisputeRequestWithImage("tx-1", 100, fromReader)); err != nil {
+		t.Fatalf("CreateP2PDispute() error = %v", err)
+	}
+	if !gotChunked {
+		t.Errorf("CreateP2PDispute() with reader image was not chunked, content length = %d", gotLength)
+	}
+}
+
+// TestDisputeBodyClosedUnread tests that closing an unread body does not start the writer and closes the caller's file
+func TestDisputeBodyClosedUnread(t *testing.T) {
+	file := &trackingReader{Reader: bytes.NewReader(pngHeader)}
+	req := P2PDisputeRequest{TransactionID: "tx-1", Amount: 100, ProofImage: ProofImage{Name: "proof.png", size: -1, reader: file, closer: file}}
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/v1/p2p_disputes/from_client", nil)
+	newDisputeBody(&req).setBody(httpReq)
+	if err := httpReq.Body.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if pr := httpReq.Body.(*lazyPipeReader).pr; pr != nil {
+		t.Errorf("Close() started the body writer")
+	}
+	if !file.closed {
+		t.Errorf("Close() did not close the proof image file")
+	}
+	if _, err := httpReq.Body.Read(make([]byte, 1)); err == nil {
+		t.Errorf("Read() after Close() error = nil")
+	}
+}
+
+// signalingFile - multipart.File для тестов, который сообщает о закрытии в канал.
+type signalingFile struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (f *signalingFile) Close() error {
+	close(f.closed)
+	return nil
+}
+
+// TestDisputeBodyClosedWhileWriting tests that closing a body after reading started closes the files not yet written
+func TestDisputeBodyClosedWhileWriting(t *testing.T) {
+	first := &signalingFile{Reader: bytes.NewReader(bytes.Repeat([]byte{1}, 1<<20)), closed: make(chan struct{})}
+	second := &signalingFile{Reader: bytes.NewReader(pngHeader), closed: make(chan struct{})}
+	req := P2PDisputeRequest{
+		TransactionID: "tx-1",
+		Amount:        100,
+		ProofImage:    ProofImage{Name: "proof.png", size: -1, reader: first, closer: first},
+		ProofImage2:   &ProofImage{Name: "proof2.png", size: -1, reader: second, closer: second},
+	}
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/v1/p2p_disputes/from_client", nil)
+	newDisputeBody(&req).setBody(httpReq)
+	if _, err := httpReq.Body.Read(make([]byte, 16)); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if err := httpReq.Body.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for name, f := range map[string]*signalingFile{"first": first, "second": second} {
+		select {
+		case <-f.closed:
+		case <-time.After(5 * time.Second):
+			t.Errorf("Close() did not close the %s proof image file", name)
+		}
+	}
+}
+
+// TestDisputeBodySeekableReaderReplay tests that a body with a seekable reader image can be recreated for redirects and retries
+func TestDisputeBodySeekableReaderReplay(t *testing.T) {
+	img, err := NewProofImageFromReader("proof.png", bytes.NewReader(pngHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := NewP2PDisputeRequestWithImage("tx-1", 100, img)
+	httpReq := httptest.NewRequest(http.MethodPost, "/v1/p2p_disputes/from_client", nil)
+	newDisputeBody(&req).setBody(httpReq)
+	if httpReq.GetBody == nil {
+		t.Fatal("setBody() GetBody = nil for a seekable reader")
+	}
+
+	first, err := io.ReadAll(httpReq.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := httpReq.GetBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := io.ReadAll(body)
+	if err != nil || !bytes.Equal(first, second) || !bytes.Contains(second, pngHeader) {
+		t.Errorf("GetBody() = %q, %v, want %q", second, err, first)
+	}
+}
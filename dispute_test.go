@@ -0,0 +1,91 @@
+package bovasdk_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestDisputeLifecycle tests GetP2PDispute, ListP2PDisputes and WaitP2PDispute
+func TestDisputeLifecycle(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+	ctx := context.Background()
+
+	tx, err := sdk.P2P.CreateP2PTransaction(ctx, *bovasdk.NewP2PTransactionRequest(
+		"user", "order-1", "payeer", "127.0.0.1", "trust", "https://example.com/callback", bovasdk.RUB, bovasdk.Card, 1000))
+	if err != nil {
+		t.Fatalf("CreateP2PTransaction() error = %v", err)
+	}
+
+	imagePath := filepath.Join(t.TempDir(), "proof.png")
+	if err = os.WriteFile(imagePath, []byte("\x89PNG\r\n\x1a\nmock"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	createDispute := func() *bovasdk.P2PDisputeResponse {
+		file, err := os.Open(imagePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := sdk.P2P.CreateP2PDispute(ctx, bovasdk.NewP2PDisputeRequest(tx.Payload.ID, 1000, "proof.png", file))
+		if err != nil {
+			t.Fatalf("CreateP2PDispute() error = %v", err)
+		}
+		return resp
+	}
+
+	first := createDispute()
+	second := createDispute()
+	if first.Data.State != bovasdk.DisputeOpened || first.Data.Repeated || !second.Data.Repeated {
+		t.Errorf("CreateP2PDispute() first = %+v, second repeated = %v", first.Data, second.Data.Repeated)
+	}
+
+	got, err := sdk.P2P.GetP2PDispute(ctx, first.Data.ID)
+	if err != nil {
+		t.Fatalf("GetP2PDispute() error = %v", err)
+	}
+	if got.Data.ID != first.Data.ID || got.Data.P2PTx.ID != tx.Payload.ID {
+		t.Errorf("GetP2PDispute() = %+v", got.Data)
+	}
+	if _, err = sdk.P2P.GetP2PDispute(ctx, 999); !bovasdk.IsNotFound(err) {
+		t.Errorf("GetP2PDispute() error = %v, want not found", err)
+	}
+
+	repeated := true
+	list, err := sdk.P2P.ListP2PDisputes(ctx, bovasdk.P2PDisputeFilter{TransactionID: tx.Payload.ID, Repeated: &repeated})
+	if err != nil {
+		t.Fatalf("ListP2PDisputes() error = %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].ID != second.Data.ID {
+		t.Errorf("ListP2PDisputes() = %+v", list.Data)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		server.SetDisputeState(first.Data.ID, bovasdk.DisputeAccepted)
+	}()
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	resolved, err := sdk.P2P.WaitP2PDispute(waitCtx, first.Data.ID, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitP2PDispute() error = %v", err)
+	}
+	if resolved.Data.State != bovasdk.DisputeAccepted {
+		t.Errorf("WaitP2PDispute() state = %s, want %s", resolved.Data.State, bovasdk.DisputeAccepted)
+	}
+
+	// временные ошибки не прерывают ожидание, interval <= 0 заменяется интервалом по умолчанию
+	server.ThrottleNext(2)
+	if _, err = sdk.P2P.WaitP2PDispute(waitCtx, first.Data.ID, 5*time.Millisecond); err != nil {
+		t.Fatalf("WaitP2PDispute() after 429 error = %v", err)
+	}
+	if _, err = sdk.P2P.WaitP2PDispute(waitCtx, first.Data.ID, 0); err != nil {
+		t.Fatalf("WaitP2PDispute(interval 0) error = %v", err)
+	}
+}
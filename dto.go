@@ -1,7 +1,12 @@
 package bovasdk
 
 import (
+	"errors"
+	"fmt"
 	"mime/multipart"
+
+	"github.com/AlexanderMikhel/bva/card"
+	"github.com/AlexanderMikhel/bva/phone"
 )
 
 // P2PTransactionRequest представляет тело запроса для создания P2P транзакции.
@@ -61,120 +66,214 @@ func (p *P2PTransactionRequest) WithRedirectURL(redirectURL string) *P2PTransact
 	return p
 }
 
+// Validate проверяет, что обязательные поля запроса на пополнение заполнены корректно.
+func (p *P2PTransactionRequest) Validate() error {
+	required := []struct{ field, value string }{
+		{"user_uuid", p.UserUUID},
+		{"merchant_id", p.MerchantID},
+		{"payeer_identifier", p.PayeerIdentifier},
+		{"payeer_ip", p.PayeerIP},
+		{"payeer_type", p.PayeerType},
+		{"callback_url", p.CallbackURL},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			return fmt.Errorf("%s is required", r.field)
+		}
+	}
+	if p.Amount <= 0 {
+		return fmt.Errorf("amount must be positive, got: %d", p.Amount)
+	}
+	if _, err := CurrencyFrom(string(p.Currency)); err != nil {
+		return err
+	}
+	if _, err := PaymentMethodFrom(string(p.PaymentMethod)); err != nil {
+		return err
+	}
+	if p.PayeerCardNumber != nil && *p.PayeerCardNumber != "" {
+		if err := card.Validate(*p.PayeerCardNumber); err != nil {
+			return fmt.Errorf("invalid payeer_card_number: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateCapability проверяет запрос по таблице возможностей: метод оплаты для валюты, обязательные для метода
+// поля и лимиты суммы. Таблица не подтверждена документацией Bova, поэтому Validate ее не использует,
+// проверку включают явно, см. BovaApiBuilder.ValidateCapabilities.
+func (p *P2PTransactionRequest) ValidateCapability() error {
+	return checkCapability(p.Currency, p.PaymentMethod, DirectionDeposit, p.Amount, p.hasField)
+}
+
+func (p *P2PTransactionRequest) hasField(field string) bool {
+	values := map[string]*string{
+		"redirect_url":       p.RedirectURL,
+		"email":              p.Email,
+		"customer_name":      p.CustomerName,
+		"payeer_card_number": p.PayeerCardNumber,
+	}
+	v, ok := values[field]
+	return ok && v != nil && *v != ""
+}
+
 // P2PTransactionResponse представляет тело ответа API для создания P2P транзакции.
 type P2PTransactionResponse struct {
-	ResultCode string `json:"result_code"`
-	Payload    struct {
-		ID                string               `json:"id"`
-		MerchantID        string               `json:"merchant_id"`
-		Currency          CurrencyEnum         `json:"currency"`
-		FormURL           string               `json:"form_url"`
-		State             TransactionStateEnum `json:"state"`
-		CreatedAt         string               `json:"created_at"`
-		UpdatedAt         string               `json:"updated_at"`
-		CloseAt           string               `json:"close_at"`
-		CallbackURL       string               `json:"callback_url"`
-		RedirectURL       string               `json:"redirect_url"`
-		Email             string               `json:"email"`
-		CustomerName      string               `json:"customer_name"`
-		Rate              string               `json:"rate"`
-		Amount            string               `json:"amount"`
-		FiatAmount        string               `json:"fiat_amount"`
-		OldFiatAmount     string               `json:"old_fiat_amount"`
-		ServiceCommission string               `json:"service_commission"`
-		TotalAmount       string               `json:"total_amount"`
-		PaymentMethod     PaymentMethodEnum    `json:"payment_method"`
-		RecipientCard     struct {
-			ID            string   `json:"id"`
-			Number        string   `json:"number"`
-			BankName      string   `json:"bank_name"`
-			BankFullName  string   `json:"bank_full_name"`
-			BankColors    struct{} `json:"bank_colors"`
-			Brand         string   `json:"brand"`
-			CardHolder    string   `json:"card_holder"`
-			PaymentMethod string   `json:"payment_method"`
-			UpdatedAt     string   `json:"updated_at"`
-			CreatedAt     string   `json:"created_at"`
-			SberpayURL    string   `json:"sberpay_url"`
-		} `json:"resipient_card"`
-	} `json:"payload"`
+	ResultCode string                `json:"result_code"`
+	Payload    P2PTransactionPayload `json:"payload"`
 }
 
-// P2PDisputeRequest представляет тело запроса для создания диспута по p2p транзакции.
-type sdkFile struct {
-	file multipart.File
-	Name string
+// P2PTransactionPayload представляет p2p транзакцию в ответах API.
+type P2PTransactionPayload struct {
+	ID                string               `json:"id"`
+	MerchantID        string               `json:"merchant_id"`
+	Currency          CurrencyEnum         `json:"currency"`
+	FormURL           string               `json:"form_url"`
+	State             TransactionStateEnum `json:"state"`
+	CreatedAt         string               `json:"created_at"`
+	UpdatedAt         string               `json:"updated_at"`
+	CloseAt           string               `json:"close_at"`
+	CallbackURL       string               `json:"callback_url"`
+	RedirectURL       string               `json:"redirect_url"`
+	Email             string               `json:"email"`
+	CustomerName      string               `json:"customer_name"`
+	Rate              string               `json:"rate"`
+	Amount            string               `json:"amount"`
+	FiatAmount        string               `json:"fiat_amount"`
+	OldFiatAmount     string               `json:"old_fiat_amount"`
+	ServiceCommission string               `json:"service_commission"`
+	TotalAmount       string               `json:"total_amount"`
+	PaymentMethod     PaymentMethodEnum    `json:"payment_method"`
+	RecipientCard     struct {
+		ID            string   `json:"id"`
+		Number        string   `json:"number"`
+		BankName      string   `json:"bank_name"`
+		BankFullName  string   `json:"bank_full_name"`
+		BankColors    struct{} `json:"bank_colors"`
+		Brand         string   `json:"brand"`
+		CardHolder    string   `json:"card_holder"`
+		PaymentMethod string   `json:"payment_method"`
+		UpdatedAt     string   `json:"updated_at"`
+		CreatedAt     string   `json:"created_at"`
+		SberpayURL    string   `json:"sberpay_url"`
+	} `json:"resipient_card"`
 }
 
+// P2PDisputeRequest представляет тело запроса для создания диспута по p2p транзакции.
 type P2PDisputeRequest struct {
 	TransactionID string
 	Amount        int
-	ProofImage    sdkFile
+	ProofImage    ProofImage
 	//not required
-	ProofImage2 *sdkFile
+	ProofImage2 *ProofImage
 }
 
+// NewP2PDisputeRequest создает запрос на диспут из multipart.File. Файл закрывается в CreateP2PDispute.
 func NewP2PDisputeRequest(TransactionID string, Amount int, fileNameWithFormat string, file multipart.File) P2PDisputeRequest {
-	return P2PDisputeRequest{TransactionID: TransactionID, Amount: Amount, ProofImage: sdkFile{Name: fileNameWithFormat, file: file}}
+	return P2PDisputeRequest{TransactionID: TransactionID, Amount: Amount, ProofImage: newProofImageFromMultipart(fileNameWithFormat, file)}
+}
+
+// NewP2PDisputeRequestWithImage создает запрос на диспут из проверенного изображения.
+// Если image равен nil, CreateP2PDispute вернет ошибку.
+func NewP2PDisputeRequestWithImage(transactionID string, amount int, image *ProofImage) P2PDisputeRequest {
+	req := P2PDisputeRequest{TransactionID: transactionID, Amount: amount}
+	if image != nil {
+		req.ProofImage = *image
+	}
+	return req
 }
 
 func (p *P2PDisputeRequest) WithProofImage2(fileNameWithFormat string, file multipart.File) *P2PDisputeRequest {
-	p.ProofImage2 = &sdkFile{file: file, Name: fileNameWithFormat}
+	image := newProofImageFromMultipart(fileNameWithFormat, file)
+	p.ProofImage2 = &image
 	return p
 }
 
-// P2PDisputeResponse представляет тело ответа API для создания диспута по p2p транзакции.
+// validate проверяет, что у запроса есть изображение, до отправки.
+func (p *P2PDisputeRequest) validate() error {
+	if !p.ProofImage.hasContent() {
+		return errors.New("proof image is required")
+	}
+	if p.ProofImage2 != nil && !p.ProofImage2.hasContent() {
+		return fmt.Errorf("proof image %q has no content", p.ProofImage2.Name)
+	}
+	return nil
+}
+
+// WithProofImage2Image задает второе изображение и возвращает обновленный запрос
+func (p *P2PDisputeRequest) WithProofImage2Image(image *ProofImage) *P2PDisputeRequest {
+	p.ProofImage2 = image
+	return p
+}
+
+// P2PDispute представляет диспут по p2p транзакции.
+type P2PDispute struct {
+	ID          int              `json:"id"`
+	State       DisputeStateEnum `json:"state"`
+	Repeated    bool             `json:"repeated"`
+	UpdatedAt   string           `json:"updated_at"`
+	CreatedAt   string           `json:"created_at"`
+	ProofImage  string           `json:"proof_image"`
+	ProofImage2 string           `json:"proof_image2"`
+	Amount      int              `json:"amount"`
+	P2PTx       struct {
+		ID               string `json:"id"`
+		MerchantID       string `json:"merchant_id"`
+		Currency         string `json:"currency"`
+		ToCurrency       string `json:"to_currency"`
+		State            string `json:"state"`
+		CreatedAt        string `json:"created_at"`
+		UpdatedAt        string `json:"updated_at"`
+		CloseAt          string `json:"close_at"`
+		RedirectURL      string `json:"redirect_url"`
+		Email            string `json:"email"`
+		CustomerName     string `json:"customer_name"`
+		Rate             string `json:"rate"`
+		Amount           string `json:"amount"`
+		FiatAmount       string `json:"fiat_amount"`
+		OldFiatAmount    string `json:"old_fiat_amount"`
+		PaymentMethod    string `json:"payment_method"`
+		PayeerBankName   string `json:"payeer_bank_name"`
+		Comment          string `json:"comment"`
+		AntifraudVerdict string `json:"antifraud_verdict"`
+		Requisities      struct {
+			Number        string                 `json:"number"`
+			CardHolder    string                 `json:"card_holder"`
+			BankName      string                 `json:"bank_name"`
+			BankFullName  string                 `json:"bank_full_name"`
+			BankColors    map[string]interface{} `json:"bank_colors"`
+			Brand         string                 `json:"brand"`
+			PaymentMethod string                 `json:"payment_method"`
+			UpdatedAt     string                 `json:"updated_at"`
+			CreatedAt     string                 `json:"created_at"`
+			ID            string                 `json:"id"`
+			SberpayURL    string                 `json:"sberpay_url"`
+		} `json:"requisities"`
+	} `json:"p2p_tx"`
+}
+
+// IsResolved возвращает true, если диспут в финальном статусе.
+func (d P2PDispute) IsResolved() bool {
+	return d.State.IsFinal()
+}
+
+// P2PDisputeResponse представляет тело ответа API для создания и получения диспута по p2p транзакции.
 type P2PDisputeResponse struct {
-	Data struct {
-		ID          int    `json:"id"`
-		State       string `json:"state"`
-		Repeated    bool   `json:"repeated"`
-		UpdatedAt   string `json:"updated_at"`
-		CreatedAt   string `json:"created_at"`
-		ProofImage  string `json:"proof_image"`
-		ProofImage2 string `json:"proof_image2"`
-		Amount      int    `json:"amount"`
-		P2PTx       struct {
-			ID               string `json:"id"`
-			MerchantID       string `json:"merchant_id"`
-			Currency         string `json:"currency"`
-			ToCurrency       string `json:"to_currency"`
-			State            string `json:"state"`
-			CreatedAt        string `json:"created_at"`
-			UpdatedAt        string `json:"updated_at"`
-			CloseAt          string `json:"close_at"`
-			RedirectURL      string `json:"redirect_url"`
-			Email            string `json:"email"`
-			CustomerName     string `json:"customer_name"`
-			Rate             string `json:"rate"`
-			Amount           string `json:"amount"`
-			FiatAmount       string `json:"fiat_amount"`
-			OldFiatAmount    string `json:"old_fiat_amount"`
-			PaymentMethod    string `json:"payment_method"`
-			PayeerBankName   string `json:"payeer_bank_name"`
-			Comment          string `json:"comment"`
-			AntifraudVerdict string `json:"antifraud_verdict"`
-			Requisities      struct {
-				Number        string                 `json:"number"`
-				CardHolder    string                 `json:"card_holder"`
-				BankName      string                 `json:"bank_name"`
-				BankFullName  string                 `json:"bank_full_name"`
-				BankColors    map[string]interface{} `json:"bank_colors"`
-				Brand         string                 `json:"brand"`
-				PaymentMethod string                 `json:"payment_method"`
-				UpdatedAt     string                 `json:"updated_at"`
-				CreatedAt     string                 `json:"created_at"`
-				ID            string                 `json:"id"`
-				SberpayURL    string                 `json:"sberpay_url"`
-			} `json:"requisities"`
-		} `json:"p2p_tx"`
-	} `json:"data"`
+	Data    P2PDispute  `json:"data"`
 	Message string      `json:"message"`
 	Status  string      `json:"status"`
 	Errors  interface{} `json:"errors"`
 	Meta    interface{} `json:"meta"`
 }
 
+// P2PDisputeListResponse представляет тело ответа API для списка диспутов.
+type P2PDisputeListResponse struct {
+	Data    []P2PDispute `json:"data"`
+	Message string       `json:"message"`
+	Status  string       `json:"status"`
+	Errors  interface{}  `json:"errors"`
+	Meta    interface{}  `json:"meta"`
+}
+
 // MassTransactionRequest представляет тело запроса для создания массовой транзакции.
 type MassTransactionRequest struct {
 	UserUUID      string            `json:"user_uuid"`
@@ -192,8 +291,9 @@ type MassTransactionRequest struct {
 }
 
 // NewMassTransactionRequest создает новый экземпляр MassTransactionRequest с обязательными параметрами.
+// Для sbp и sbp_fast toCard - номер телефона, он приводится к формату Bova, например "8 (916) 123-45-67" -> "79161234567".
 func NewMassTransactionRequest(userUUID, merchantID, toCard, callbackURL string, amount int, currency CurrencyEnum, paymentMethod PaymentMethodEnum) *MassTransactionRequest {
-	return &MassTransactionRequest{
+	req := &MassTransactionRequest{
 		UserUUID:      userUUID,
 		MerchantID:    merchantID,
 		ToCard:        toCard,
@@ -202,6 +302,9 @@ func NewMassTransactionRequest(userUUID, merchantID, toCard, callbackURL string,
 		Currency:      currency,
 		PaymentMethod: paymentMethod,
 	}
+	// номер, который невозможен, остается как есть, ошибку вернет Validate
+	_ = req.normalizePhone()
+	return req
 }
 
 func (m *MassTransactionRequest) WithSbpBankName(sbpBankName string) *MassTransactionRequest {
@@ -226,25 +329,122 @@ func (m *MassTransactionRequest) WithRecipientLastName(recipientLastName string)
 
 // MassTransactionResponse представляет тело ответа API для создания массовой транзакции.
 type MassTransactionResponse struct {
-	ResultCode string `json:"result_code"`
-	Payload    struct {
-		ID                string            `json:"id"`
-		MerchantId        string            `json:"merchant_id"`
-		State             string            `json:"state"`
-		CreatedAt         string            `json:"created_at"`
-		UpdatedAt         string            `json:"updated_at"`
-		Currency          string            `json:"currency"`
-		CallBackUrl       string            `json:"callback_url"`
-		Amount            string            `json:"amount"`
-		FiatAmount        string            `json:"fiat_amount"`
-		OldFiatAmount     string            `json:"old_fiat_amount"`
-		Rate              string            `json:"rate"`
-		CommissionType    string            `json:"commission_type"`
-		ServiceCommission string            `json:"service_commission"`
-		TotalAmount       string            `json:"total_amount"`
-		BankName          string            `json:"bank_name"`
-		SbpBankName       string            `json:"sbp_bank_name"`
-		PaymentMethod     PaymentMethodEnum `json:"payment_method"`
-		RecipientCard     string            `json:"recipient_card"`
-	} `json:"payload"`
+	ResultCode string                 `json:"result_code"`
+	Payload    MassTransactionPayload `json:"payload"`
+}
+
+// MassTransactionPayload представляет массовую транзакцию (выплату) в ответах API.
+type MassTransactionPayload struct {
+	ID                string            `json:"id"`
+	MerchantId        string            `json:"merchant_id"`
+	State             string            `json:"state"`
+	CreatedAt         string            `json:"created_at"`
+	UpdatedAt         string            `json:"updated_at"`
+	Currency          string            `json:"currency"`
+	CallBackUrl       string            `json:"callback_url"`
+	Amount            string            `json:"amount"`
+	FiatAmount        string            `json:"fiat_amount"`
+	OldFiatAmount     string            `json:"old_fiat_amount"`
+	Rate              string            `json:"rate"`
+	CommissionType    string            `json:"commission_type"`
+	ServiceCommission string            `json:"service_commission"`
+	TotalAmount       string            `json:"total_amount"`
+	BankName          string            `json:"bank_name"`
+	SbpBankName       string            `json:"sbp_bank_name"`
+	PaymentMethod     PaymentMethodEnum `json:"payment_method"`
+	RecipientCard     string            `json:"recipient_card"`
+}
+
+// Validate проверяет, что обязательные поля запроса на выплату заполнены корректно.
+func (m *MassTransactionRequest) Validate() error {
+	if m.UserUUID == "" {
+		return fmt.Errorf("user_uuid is required")
+	}
+	if m.MerchantID == "" {
+		return fmt.Errorf("merchant_id is required")
+	}
+	if m.CallbackURL == "" {
+		return fmt.Errorf("callback_url is required")
+	}
+	if m.ToCard == "" {
+		return fmt.Errorf("to_card is required")
+	}
+	if m.Amount <= 0 {
+		return fmt.Errorf("amount must be positive, got: %d", m.Amount)
+	}
+	if _, err := CurrencyFrom(string(m.Currency)); err != nil {
+		return err
+	}
+	if _, err := PaymentMethodFrom(string(m.PaymentMethod)); err != nil {
+		return err
+	}
+	if m.PaymentMethod == Card {
+		if err := card.Validate(m.ToCard); err != nil {
+			return fmt.Errorf("invalid to_card: %w", err)
+		}
+	}
+	if m.isPhonePayout() {
+		if _, err := phone.Normalize(m.ToCard, phoneRegion(m.Currency)); isImpossiblePhone(err) {
+			return fmt.Errorf("invalid to_card phone number: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateCapability проверяет запрос по таблице возможностей: метод выплаты для валюты, обязательные для метода
+// поля и лимиты суммы. Таблица не подтверждена документацией Bova, поэтому Validate ее не использует,
+// проверку включают явно, см. BovaApiBuilder.ValidateCapabilities.
+func (m *MassTransactionRequest) ValidateCapability() error {
+	return checkCapability(m.Currency, m.PaymentMethod, DirectionPayout, m.Amount, m.hasField)
+}
+
+// isPhonePayout возвращает true, если в ToCard передается номер телефона.
+func (m *MassTransactionRequest) isPhonePayout() bool {
+	return m.PaymentMethod == Sbp || m.PaymentMethod == SbpFast
+}
+
+// normalizePhone приводит номер телефона в ToCard к формату E.164 без "+" для выплат по СБП.
+// Для невозможного номера возвращается ошибка. Формат номера не описан в документации Bova, поэтому
+// номер, который просто не похож на мобильный, остается как есть и проверяется API.
+func (m *MassTransactionRequest) normalizePhone() error {
+	if !m.isPhonePayout() {
+		return nil
+	}
+	n, err := phone.Normalize(m.ToCard, phoneRegion(m.Currency))
+	switch {
+	case err == nil:
+		m.ToCard = n.Bova()
+	case isImpossiblePhone(err):
+		return fmt.Errorf("invalid to_card phone number: %w", err)
+	}
+	return nil
+}
+
+// isImpossiblePhone возвращает true, если номер не может быть номером телефона: в нем буквы,
+// неверное число цифр или код страны, для которой нет выплат по СБП.
+func isImpossiblePhone(err error) bool {
+	return errors.Is(err, phone.ErrInvalidCharacters) || errors.Is(err, phone.ErrInvalidLength) ||
+		errors.Is(err, phone.ErrUnsupportedRegion)
+}
+
+// phoneRegion возвращает страну для номеров телефона без кода страны по валюте выплаты.
+func phoneRegion(currency CurrencyEnum) phone.Region {
+	switch currency {
+	case UZS:
+		return phone.UZ
+	case KRW:
+		return phone.KR
+	}
+	return phone.RU
+}
+
+func (m *MassTransactionRequest) hasField(field string) bool {
+	values := map[string]*string{
+		"sbp_bank_name":        m.SbpBankName,
+		"bank_name":            m.BankName,
+		"recipient_first_name": m.RecipientFirstName,
+		"recipient_last_name":  m.RecipientLastName,
+	}
+	v, ok := values[field]
+	return ok && v != nil && *v != ""
 }
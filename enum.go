@@ -1,68 +1,161 @@
 package bovasdk
 
-import "fmt"
-
 type CurrencyEnum string
 type PaymentMethodEnum string
 type TransactionStateEnum string
+type DisputeStateEnum string
+
+var (
+	currencies        = newEnumRegistry("currency", RUB, UZS, KRW)
+	paymentMethods    = newEnumRegistry("payment method", Card, SberPay, Sbp, SbpFast, AccountNumber)
+	transactionStates = newEnumRegistry("transaction state", WaitingPayment, Paid, Failed, ClosedFailed, RepeatedClosedFailed,
+		Successed, AcceptedSuccessed, RepeatedAcceptedSuccessed, Reviewing, RepeatedReviewing)
+	disputeStates = newEnumRegistry("dispute state", DisputeOpened, DisputeReviewing, DisputeAccepted, DisputeRejected, DisputeCanceled)
+)
 
+// CurrencyFrom разбирает валюту. Для неизвестного значения возвращается само значение и *UnknownEnumValueError.
 func CurrencyFrom(val string) (CurrencyEnum, error) {
-	switch val {
-	case string(RUB):
-		return RUB, nil
-	case string(UZS):
-		return UZS, nil
-	case string(KRW):
-		return KRW, nil
-	default:
-		return "", fmt.Errorf("invalid currency value: %s", val)
-	}
+	return currencies.parse(val)
 }
 
+// PaymentMethodFrom разбирает метод оплаты. Для неизвестного значения возвращается само значение и *UnknownEnumValueError.
 func PaymentMethodFrom(val string) (PaymentMethodEnum, error) {
-	switch val {
-	case string(Card):
-		return Card, nil
-	case string(SberPay):
-		return SberPay, nil
-	case string(Sbp):
-		return Sbp, nil
-	case string(SbpFast):
-		return SbpFast, nil
-	case string(AccountNumber):
-		return AccountNumber, nil
+	return paymentMethods.parse(val)
+}
+
+// TransactionStateFrom разбирает статус транзакции. Для неизвестного значения возвращается само значение и *UnknownEnumValueError.
+func TransactionStateFrom(val string) (TransactionStateEnum, error) {
+	return transactionStates.parse(val)
+}
+
+// DisputeStateFrom разбирает статус диспута. Для неизвестного значения возвращается само значение и *UnknownEnumValueError.
+func DisputeStateFrom(val string) (DisputeStateEnum, error) {
+	return disputeStates.parse(val)
+}
+
+// RegisterCurrency добавляет валюты, которые Bova уже поддерживает, а SDK еще нет.
+func RegisterCurrency(values ...CurrencyEnum) {
+	currencies.register(values...)
+}
+
+// RegisterPaymentMethod добавляет методы оплаты, которые Bova уже поддерживает, а SDK еще нет.
+func RegisterPaymentMethod(values ...PaymentMethodEnum) {
+	paymentMethods.register(values...)
+}
+
+// RegisterTransactionState добавляет статусы транзакций, которые Bova уже поддерживает, а SDK еще нет.
+func RegisterTransactionState(values ...TransactionStateEnum) {
+	transactionStates.register(values...)
+}
+
+// RegisterDisputeState добавляет статусы диспутов, которые Bova уже поддерживает, а SDK еще нет.
+func RegisterDisputeState(values ...DisputeStateEnum) {
+	disputeStates.register(values...)
+}
+
+// CurrencyValues возвращает все известные валюты.
+func CurrencyValues() []CurrencyEnum {
+	return currencies.list()
+}
+
+// PaymentMethodValues возвращает все известные методы оплаты.
+func PaymentMethodValues() []PaymentMethodEnum {
+	return paymentMethods.list()
+}
+
+// TransactionStateValues возвращает все известные статусы транзакций.
+func TransactionStateValues() []TransactionStateEnum {
+	return transactionStates.list()
+}
+
+// DisputeStateValues возвращает все известные статусы диспутов.
+func DisputeStateValues() []DisputeStateEnum {
+	return disputeStates.list()
+}
+
+func (c CurrencyEnum) IsKnown() bool     { return currencies.isKnown(c) }
+func (c CurrencyEnum) checkKnown() error { return currencies.check(c) }
+
+func (m PaymentMethodEnum) IsKnown() bool     { return paymentMethods.isKnown(m) }
+func (m PaymentMethodEnum) checkKnown() error { return paymentMethods.check(m) }
+
+func (s TransactionStateEnum) IsKnown() bool     { return transactionStates.isKnown(s) }
+func (s TransactionStateEnum) checkKnown() error { return transactionStates.check(s) }
+
+func (s DisputeStateEnum) IsKnown() bool     { return disputeStates.isKnown(s) }
+func (s DisputeStateEnum) checkKnown() error { return disputeStates.check(s) }
+
+// IsFinal возвращает true, если диспут рассмотрен и его статус больше не изменится.
+func (s DisputeStateEnum) IsFinal() bool {
+	switch s {
+	case DisputeAccepted, DisputeRejected, DisputeCanceled:
+		return true
 	default:
-		return "", fmt.Errorf("invalid payment method value: %s", val)
+		return false
 	}
 }
 
-func TransactionStateFrom(val string) (TransactionStateEnum, error) {
-	switch val {
-	case string(WaitingPayment):
-		return WaitingPayment, nil
-	case string(Paid):
-		return Paid, nil
-	case string(Failed):
-		return Failed, nil
-	case string(ClosedFailed):
-		return ClosedFailed, nil
-	case string(RepeatedClosedFailed):
-		return RepeatedClosedFailed, nil
-	case string(Successed):
-		return Successed, nil
-	case string(AcceptedSuccessed):
-		return AcceptedSuccessed, nil
-	case string(RepeatedAcceptedSuccessed):
-		return RepeatedAcceptedSuccessed, nil
-	case string(Reviewing):
-		return Reviewing, nil
-	case string(RepeatedReviewing):
-		return RepeatedReviewing, nil
+// IsSuccess возвращает true для успешных статусов транзакции.
+func (s TransactionStateEnum) IsSuccess() bool {
+	switch s {
+	case Successed, AcceptedSuccessed, RepeatedAcceptedSuccessed:
+		return true
 	default:
-		return "", fmt.Errorf("invalid transaction state value: %s", val)
+		return false
 	}
 }
 
+// transactionStateRank задает порядок статусов транзакции: оплата, результат,
+// рассмотрение диспута, итог диспута, повторный диспут и его итог.
+var transactionStateRank = map[TransactionStateEnum]int{
+	WaitingPayment:            0,
+	Paid:                      1,
+	Successed:                 2,
+	Failed:                    2,
+	Reviewing:                 3,
+	AcceptedSuccessed:         4,
+	ClosedFailed:              4,
+	RepeatedReviewing:         5,
+	RepeatedAcceptedSuccessed: 6,
+	RepeatedClosedFailed:      6,
+}
+
+// CanTransitionTo возвращает true, если транзакция может перейти из текущего статуса в next.
+// Для неизвестных статусов порядок не определен и переход разрешается.
+func (s TransactionStateEnum) CanTransitionTo(next TransactionStateEnum) bool {
+	from, okFrom := transactionStateRank[s]
+	to, okTo := transactionStateRank[next]
+	if !okFrom || !okTo {
+		return s != next
+	}
+	return to > from
+}
+
+// IsTerminal возвращает true, если статус транзакции больше не изменится без диспута.
+func (s TransactionStateEnum) IsTerminal() bool {
+	rank, ok := transactionStateRank[s]
+	return ok && rank >= transactionStateRank[Successed] && s != Reviewing && s != RepeatedReviewing
+}
+
+var disputeStateRank = map[DisputeStateEnum]int{
+	DisputeOpened:    0,
+	DisputeReviewing: 1,
+	DisputeAccepted:  2,
+	DisputeRejected:  2,
+	DisputeCanceled:  2,
+}
+
+// CanTransitionTo возвращает true, если диспут может перейти из текущего статуса в next.
+// Для неизвестных статусов порядок не определен и переход разрешается.
+func (s DisputeStateEnum) CanTransitionTo(next DisputeStateEnum) bool {
+	from, okFrom := disputeStateRank[s]
+	to, okTo := disputeStateRank[next]
+	if !okFrom || !okTo {
+		return s != next
+	}
+	return to > from
+}
+
 const (
 	RUB CurrencyEnum = "rub"
 	UZS CurrencyEnum = "uzs"
@@ -84,4 +177,10 @@ const (
 	RepeatedAcceptedSuccessed TransactionStateEnum = "repeated_accepted_successed"
 	Reviewing                 TransactionStateEnum = "reviewing"
 	RepeatedReviewing         TransactionStateEnum = "repeated_reviewing"
+
+	DisputeOpened    DisputeStateEnum = "opened"
+	DisputeReviewing DisputeStateEnum = "reviewing"
+	DisputeAccepted  DisputeStateEnum = "accepted"
+	DisputeRejected  DisputeStateEnum = "rejected"
+	DisputeCanceled  DisputeStateEnum = "canceled"
 )
@@ -0,0 +1,129 @@
+package bovasdk
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// UnknownEnumValueError возвращается для значения, которого нет в реестре перечисления.
+type UnknownEnumValueError struct {
+	Enum  string
+	Value string
+}
+
+func (e *UnknownEnumValueError) Error() string {
+	return fmt.Sprintf("invalid %s value: %s", e.Enum, e.Value)
+}
+
+// enumRegistry хранит известные значения перечисления в порядке регистрации.
+type enumRegistry[T ~string] struct {
+	name   string
+	mu     sync.RWMutex
+	values []T
+	known  map[T]struct{}
+}
+
+func newEnumRegistry[T ~string](name string, values ...T) *enumRegistry[T] {
+	r := &enumRegistry[T]{name: name, known: make(map[T]struct{}, len(values))}
+	r.register(values...)
+	return r
+}
+
+func (r *enumRegistry[T]) register(values ...T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range values {
+		if _, ok := r.known[v]; ok || v == "" {
+			continue
+		}
+		r.known[v] = struct{}{}
+		r.values = append(r.values, v)
+	}
+}
+
+func (r *enumRegistry[T]) isKnown(v T) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.known[v]
+	return ok
+}
+
+func (r *enumRegistry[T]) list() []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]T(nil), r.values...)
+}
+
+// parse возвращает значение даже если оно неизвестно, чтобы вызывающий код мог решить, что с ним делать.
+func (r *enumRegistry[T]) parse(val string) (T, error) {
+	v := T(val)
+	if !r.isKnown(v) {
+		return v, &UnknownEnumValueError{Enum: r.name, Value: val}
+	}
+	return v, nil
+}
+
+// check возвращает *UnknownEnumValueError для непустого неизвестного значения.
+func (r *enumRegistry[T]) check(v T) error {
+	if v != "" && !r.isKnown(v) {
+		return &UnknownEnumValueError{Enum: r.name, Value: string(v)}
+	}
+	return nil
+}
+
+// enumValue реализуют перечисления SDK, значения которых проверяются при строгой проверке.
+type enumValue interface {
+	checkKnown() error
+}
+
+var enumValueType = reflect.TypeOf((*enumValue)(nil)).Elem()
+
+// checkEnums обходит v и возвращает ошибку для первого неизвестного значения перечисления.
+// Неэкспортируемые поля не проверяются.
+func checkEnums(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	return checkEnumValue(reflect.ValueOf(v))
+}
+
+func checkEnumValue(v reflect.Value) error {
+	if v.Type().Implements(enumValueType) && v.CanInterface() {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil
+		}
+		return v.Interface().(enumValue).checkKnown()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return checkEnumValue(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := checkEnumValue(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkEnumValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := checkEnumValue(iter.Value()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
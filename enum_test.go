@@ -0,0 +1,81 @@
+package bovasdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestEnumUnknownValues tests that unknown enum values round-trip and can be registered
+func TestEnumUnknownValues(t *testing.T) {
+	state, err := TransactionStateFrom("expired_test")
+	var unknownErr *UnknownEnumValueError
+	if !errors.As(err, &unknownErr) || state != "expired_test" {
+		t.Fatalf("TransactionStateFrom() = %q, %v, want value with UnknownEnumValueError", state, err)
+	}
+	if state.IsKnown() || !Paid.IsKnown() {
+		t.Errorf("IsKnown() mismatch for %q and %q", state, Paid)
+	}
+
+	var payload struct {
+		State TransactionStateEnum `json:"state"`
+	}
+	if err = json.Unmarshal([]byte(`{"state":"expired_test"}`), &payload); err != nil || payload.State != "expired_test" {
+		t.Fatalf("Unmarshal() = %q, %v", payload.State, err)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil || string(data) != `{"state":"expired_test"}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+	if err = checkEnums(&payload); !errors.As(err, &unknownErr) {
+		t.Errorf("checkEnums() error = %v, want UnknownEnumValueError", err)
+	}
+
+	// отдельный реестр, чтобы не менять глобальный список валют для остальных тестов
+	registry := newEnumRegistry("currency", RUB, UZS)
+	if _, err = registry.parse("kzt_test"); err == nil {
+		t.Errorf("parse() before register error = nil")
+	}
+	registry.register("kzt_test")
+	if _, err = registry.parse("kzt_test"); err != nil {
+		t.Errorf("parse() after register error = %v", err)
+	}
+	values := registry.list()
+	if values[0] != RUB || values[len(values)-1] != "kzt_test" {
+		t.Errorf("list() = %v", values)
+	}
+}
+
+// TestStrictEnumsCallOption tests that strict enum validation is enabled per client and overridden per call
+func TestStrictEnumsCallOption(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"result_code":"ok","payload":{"id":"tx-1","state":"expired_test"}}`))
+	}))
+	defer server.Close()
+	p2p := p2pNew(newTransport(server.URL, NewEncoder("mock_api_secret"), server.Client()))
+	ctx := context.Background()
+
+	if _, err := p2p.GetP2PTransaction(ctx, "tx-1"); err != nil {
+		t.Fatalf("GetP2PTransaction() error = %v", err)
+	}
+	var unknownErr *UnknownEnumValueError
+	if _, err := p2p.GetP2PTransaction(ctx, "tx-1", WithStrictEnums(true)); !errors.As(err, &unknownErr) {
+		t.Errorf("GetP2PTransaction() strict error = %v, want UnknownEnumValueError", err)
+	}
+
+	p2p.transport.strictEnums = true
+	if _, err := p2p.GetP2PTransaction(ctx, "tx-1", WithStrictEnums(false)); err != nil {
+		t.Errorf("GetP2PTransaction() with strict client and lenient call error = %v", err)
+	}
+
+	req := NewP2PTransactionRequest(userUUID, "order-1", "payeer", "127.0.0.1", "trust", "https://example.com/callback", "kzt_test", Card, 1000)
+	before := requests
+	if _, err := p2p.CreateP2PTransaction(ctx, *req); !errors.As(err, &unknownErr) || requests != before {
+		t.Errorf("CreateP2PTransaction() strict error = %v, requests sent = %d, want UnknownEnumValueError before send", err, requests-before)
+	}
+}
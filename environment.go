@@ -0,0 +1,85 @@
+package bovasdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Environment - окружение Bova API.
+type Environment struct {
+	Name string
+	URL  string
+}
+
+var (
+	// Sandbox - тестовое окружение, операции в нем не двигают реальные деньги.
+	Sandbox = Environment{Name: "sandbox", URL: "https://sandbox.bovatech.cc"}
+	// Production - боевое окружение. URL не подтвержден документацией Bova, а выведен из URL sandbox,
+	// если боевой URL из договора другой, задайте его через Production.WithURL.
+	Production = Environment{Name: "production", URL: "https://bovatech.cc"}
+)
+
+// customEnvironmentName - имя окружения для URL, не совпадающего ни с одним известным окружением.
+const customEnvironmentName = "custom"
+
+// ErrEnvironmentMismatch возвращается для изменяющих запросов, если ключ выпущен для другого окружения,
+// и при сборке клиента, если объявленное окружение не совпадает с окружением его URL.
+// Ключи Bova - hex строки без признака окружения, поэтому окружение ключа нужно задать явно
+// через SecretEnvironment, иначе проверка не выполняется.
+var ErrEnvironmentMismatch = errors.New("secret environment does not match API environment")
+
+// EnvironmentFrom возвращает известное окружение по имени, например "sandbox".
+func EnvironmentFrom(name string) (Environment, bool) {
+	for _, env := range []Environment{Sandbox, Production} {
+		if strings.EqualFold(name, env.Name) {
+			return env, true
+		}
+	}
+	return Environment{}, false
+}
+
+// WithURL возвращает окружение с тем же именем и другим URL, например для прокси
+// или mock сервера, который должен считаться sandbox.
+func (e Environment) WithURL(url string) Environment {
+	e.URL = url
+	return e
+}
+
+// IsProduction возвращает true для боевого окружения.
+func (e Environment) IsProduction() bool {
+	return e.Name == Production.Name
+}
+
+// environmentOf возвращает известное окружение по URL или окружение custom.
+func environmentOf(apiURL string) Environment {
+	url := strings.TrimRight(apiURL, "/")
+	for _, env := range []Environment{Sandbox, Production} {
+		if url == env.URL {
+			return env
+		}
+	}
+	return Environment{Name: customEnvironmentName, URL: apiURL}
+}
+
+// checkDeclared проверяет, что URL объявленного окружения не принадлежит другому известному окружению,
+// например Environment(Production.WithURL(Sandbox.URL)).
+func (e Environment) checkDeclared() error {
+	known := environmentOf(e.URL)
+	if known.Name != customEnvironmentName && known.Name != e.Name {
+		return fmt.Errorf("%w: %s environment is declared for %s URL %s", ErrEnvironmentMismatch, e.Name, known.Name, e.URL)
+	}
+	return nil
+}
+
+// environmentGuard запрещает запросы, изменяющие данные (все, кроме GET и HEAD),
+// например создание выплат ключом sandbox в production и наоборот.
+func environmentGuard(api, secret Environment) Middleware {
+	return BeforeSend(func(req *http.Request) error {
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			return nil
+		}
+		return fmt.Errorf("%w: %s secret is used against %s, %s %s is refused", ErrEnvironmentMismatch, secret.Name, api.Name, req.Method, req.URL.Path)
+	})
+}
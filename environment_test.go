@@ -0,0 +1,77 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// TestEnvironmentGuard tests that write requests are refused when the secret belongs to another environment
+func TestEnvironmentGuard(t *testing.T) {
+	sent := 0
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent++
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"payload":{"id":"1"}}`))}, nil
+	})}
+	build := func(b *bovasdk.BovaApiBuilder) *bovasdk.BovaApi {
+		t.Helper()
+		sdk, err := b.Secret(testSecret).Client(client).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sdk
+	}
+	ctx := context.Background()
+	req := *bovasdk.NewMassTransactionRequest("user", "p-1", "4111111111111111", "https://example.com", 100, bovasdk.RUB, bovasdk.Card)
+
+	sdk := build(bovasdk.NewBovaApiBuilder().Environment(bovasdk.Production).SecretEnvironment(bovasdk.Sandbox))
+	if env := sdk.Environment(); !env.IsProduction() {
+		t.Errorf("Environment() = %+v, want production", env)
+	}
+	if _, err := sdk.MassTransaction.CreateMassTransaction(ctx, req); !errors.Is(err, bovasdk.ErrEnvironmentMismatch) {
+		t.Errorf("CreateMassTransaction() error = %v, want ErrEnvironmentMismatch", err)
+	}
+	if _, err := sdk.MassTransaction.GetMassTransaction(ctx, "1"); err != nil {
+		t.Errorf("GetMassTransaction() error = %v, reads must be allowed", err)
+	}
+	if sent != 1 {
+		t.Errorf("sent requests = %d, want only the read", sent)
+	}
+
+	sdk = build(bovasdk.NewBovaApiBuilder().ApiURL("https://sandbox.bovatech.cc/").SecretEnvironment(bovasdk.Sandbox))
+	if _, err := sdk.MassTransaction.CreateMassTransaction(ctx, req); err != nil || sdk.Environment() != bovasdk.Sandbox {
+		t.Errorf("CreateMassTransaction() in sandbox = %v, environment = %+v", err, sdk.Environment())
+	}
+
+	sdk = build(bovasdk.NewBovaApiBuilder().Environment(bovasdk.Sandbox).SecretEnvironment(bovasdk.Production).AllowEnvironmentMismatch())
+	if _, err := sdk.MassTransaction.CreateMassTransaction(ctx, req); err != nil {
+		t.Errorf("CreateMassTransaction() with override error = %v", err)
+	}
+
+	sdk = build(bovasdk.NewBovaApiBuilder().Environment(bovasdk.Sandbox.WithURL("http://localhost:8080")).SecretEnvironment(bovasdk.Sandbox))
+	if _, err := sdk.MassTransaction.CreateMassTransaction(ctx, req); err != nil || sdk.Environment().Name != bovasdk.Sandbox.Name {
+		t.Errorf("CreateMassTransaction() with declared custom URL = %v, environment = %+v", err, sdk.Environment())
+	}
+
+	// ключ Bova не содержит признака окружения, без SecretEnvironment проверка не выполняется
+	sdk = build(bovasdk.NewBovaApiBuilder().Environment(bovasdk.Production))
+	if _, err := sdk.MassTransaction.CreateMassTransaction(ctx, req); err != nil {
+		t.Errorf("CreateMassTransaction() without secret environment error = %v", err)
+	}
+
+	// объявленное окружение сверяется с его URL
+	if _, err := bovasdk.NewBovaApiBuilder().Environment(bovasdk.Production.WithURL(bovasdk.Sandbox.URL)).Secret(testSecret).Build(); !errors.Is(err, bovasdk.ErrEnvironmentMismatch) {
+		t.Errorf("Build() with production declared for the sandbox URL error = %v, want ErrEnvironmentMismatch", err)
+	}
+}
@@ -0,0 +1,43 @@
+package bovasdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// APIError описывает ответ API с кодом, отличным от 200.
+type APIError struct {
+	StatusCode int
+	Body       []byte
+	Header     http.Header
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("received non-200 response code: %v, reason: %s", e.StatusCode, string(e.Body))
+}
+
+// IsNotFound возвращает true, если API ответило, что объект не найден, или поиск по merchant_id ничего не нашел.
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrTransactionNotFound) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// isRetryable возвращает true для временных ошибок: 408, 429, 5xx или сбоя соединения.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,192 @@
+package bovasdk
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// defaultListPerPage - размер страницы списка, если он не задан в фильтре.
+const defaultListPerPage = 100
+
+// TransactionFilter задает фильтры для списков p2p транзакций и выплат. Пустые поля не фильтруют.
+// Параметры запроса (merchant_id, state, currency, payment_method, created_from, created_to, page, per_page)
+// не описаны в документации Bova и являются предположением: API может не применить фильтр или пагинацию.
+type TransactionFilter struct {
+	MerchantID    string
+	State         TransactionStateEnum
+	Currency      CurrencyEnum
+	PaymentMethod PaymentMethodEnum
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	PerPage       int
+}
+
+func (f TransactionFilter) query(page int) url.Values {
+	q := url.Values{}
+	if f.MerchantID != "" {
+		q.Set("merchant_id", f.MerchantID)
+	}
+	if f.State != "" {
+		q.Set("state", string(f.State))
+	}
+	if f.Currency != "" {
+		q.Set("currency", string(f.Currency))
+	}
+	if f.PaymentMethod != "" {
+		q.Set("payment_method", string(f.PaymentMethod))
+	}
+	if !f.CreatedFrom.IsZero() {
+		q.Set("created_from", f.CreatedFrom.Format(time.RFC3339))
+	}
+	if !f.CreatedTo.IsZero() {
+		q.Set("created_to", f.CreatedTo.Format(time.RFC3339))
+	}
+	perPage := f.PerPage
+	if perPage <= 0 {
+		perPage = defaultListPerPage
+	}
+	q.Set("page", strconv.Itoa(page))
+	q.Set("per_page", strconv.Itoa(perPage))
+	return q
+}
+
+// ListMeta - информация о странице списка. Формат meta не описан в документации Bova и является предположением,
+// без total_pages итератор считает список законченным на неполной странице.
+type ListMeta struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	TotalPages int `json:"total_pages"`
+	TotalCount int `json:"total_count"`
+}
+
+// P2PTransactionListResponse представляет тело ответа API для страницы списка p2p транзакций.
+type P2PTransactionListResponse struct {
+	ResultCode string                  `json:"result_code"`
+	Payload    []P2PTransactionPayload `json:"payload"`
+	Meta       ListMeta                `json:"meta"`
+}
+
+// MassTransactionListResponse представляет тело ответа API для страницы списка выплат.
+type MassTransactionListResponse struct {
+	ResultCode string                   `json:"result_code"`
+	Payload    []MassTransactionPayload `json:"payload"`
+	Meta       ListMeta                 `json:"meta"`
+}
+
+// ListIterator последовательно обходит все страницы списка:
+//
+//	it := sdk.P2P.ListP2PTransactions(ctx, filter)
+//	for it.Next() {
+//		tx := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// Страницы загружаются по мере обхода. Сам итератор запросы не повторяет, при ответе 429 или 5xx
+// обход останавливается с ошибкой, для повторов подключите RetryMiddleware.
+type ListIterator[T any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, page int) ([]T, ListMeta, error)
+
+	items []T
+	index int
+	page  int
+	meta  ListMeta
+	done  bool
+	err   error
+}
+
+func newListIterator[T any](ctx context.Context, fetch func(ctx context.Context, page int) ([]T, ListMeta, error)) *ListIterator[T] {
+	return &ListIterator[T]{ctx: ctx, fetch: fetch, index: -1}
+}
+
+// Next переходит к следующему элементу и возвращает false, когда элементы закончились или произошла ошибка.
+func (it *ListIterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.index+1 >= len(it.items) {
+		if it.done {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		if err := it.loadPage(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+	it.index++
+	return true
+}
+
+// Item возвращает текущий элемент.
+func (it *ListIterator[T]) Item() T {
+	return it.items[it.index]
+}
+
+// Meta возвращает информацию о последней загруженной странице.
+func (it *ListIterator[T]) Meta() ListMeta {
+	return it.meta
+}
+
+// Err возвращает ошибку, на которой остановился обход.
+func (it *ListIterator[T]) Err() error {
+	return it.err
+}
+
+func (it *ListIterator[T]) loadPage() error {
+	page := it.page + 1
+	items, meta, err := it.fetch(it.ctx, page)
+	if err != nil {
+		return err
+	}
+
+	it.page, it.meta = page, meta
+	it.items, it.index = items, -1
+	// без total_pages список считается законченным на неполной странице
+	if meta.TotalPages > 0 {
+		it.done = page >= meta.TotalPages
+	} else {
+		it.done = len(items) == 0 || (meta.PerPage > 0 && len(items) < meta.PerPage)
+	}
+	return nil
+}
+
+// ListP2PTransactions возвращает итератор по p2p транзакциям, подходящим под фильтр.
+// Эндпоинт GET /v1/p2p_transactions не описан в документации Bova и может отсутствовать, тогда возвращается ошибка API.
+func (p2p *P2P) ListP2PTransactions(ctx context.Context, filter TransactionFilter, opts ...CallOption) *ListIterator[P2PTransactionPayload] {
+	return newListIterator(ctx, func(ctx context.Context, page int) ([]P2PTransactionPayload, ListMeta, error) {
+		resp, err := execute[P2PTransactionListResponse](ctx, p2p.transport, apiCall{
+			method: http.MethodGet,
+			path:   "/v1/p2p_transactions",
+			query:  filter.query(page),
+		}, opts...)
+		if err != nil {
+			return nil, ListMeta{}, err
+		}
+		return resp.Payload, resp.Meta, nil
+	})
+}
+
+// ListMassTransactions возвращает итератор по выплатам, подходящим под фильтр.
+// Эндпоинт GET /v1/mass_transactions не описан в документации Bova и может отсутствовать, тогда возвращается ошибка API.
+func (mt *MassTransaction) ListMassTransactions(ctx context.Context, filter TransactionFilter, opts ...CallOption) *ListIterator[MassTransactionPayload] {
+	return newListIterator(ctx, func(ctx context.Context, page int) ([]MassTransactionPayload, ListMeta, error) {
+		resp, err := execute[MassTransactionListResponse](ctx, mt.transport, apiCall{
+			method: http.MethodGet,
+			path:   "/v1/mass_transactions",
+			query:  filter.query(page),
+		}, opts...)
+		if err != nil {
+			return nil, ListMeta{}, err
+		}
+		return resp.Payload, resp.Meta, nil
+	})
+}
@@ -0,0 +1,110 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestListP2PTransactions tests pagination, filters and rate limit handling of ListP2PTransactions
+func TestListP2PTransactions(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+
+	for i := 1; i <= 5; i++ {
+		var tx bovasdk.P2PTransactionResponse
+		tx.Payload.ID, tx.Payload.MerchantID, tx.Payload.Currency = fmt.Sprintf("p2p-%d", i), fmt.Sprintf("order-%d", i), bovasdk.RUB
+		if i == 3 {
+			tx.Payload.Currency = bovasdk.UZS
+		}
+		server.AddP2PTransaction(tx)
+	}
+	sdk := newTestSDK(t, server)
+
+	// итератор сам не повторяет 429, это делает RetryMiddleware
+	server.ThrottleNext(1)
+	it := sdk.P2P.ListP2PTransactions(context.Background(), bovasdk.TransactionFilter{})
+	if it.Next() || !isStatus(it.Err(), http.StatusTooManyRequests) {
+		t.Fatalf("ListP2PTransactions() after 429 error = %v, want 429", it.Err())
+	}
+
+	logger, err := bovasdk.NewLogger(false, "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk, err = bovasdk.NewBovaApiBuilder().ApiURL(server.URL).Secret(testSecret).Logger(logger).
+		Middleware(bovasdk.RetryMiddleware(3, time.Millisecond)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.ThrottleNext(1)
+	it = sdk.P2P.ListP2PTransactions(context.Background(), bovasdk.TransactionFilter{Currency: bovasdk.RUB, PerPage: 2})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Item().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("ListP2PTransactions() error = %v", err)
+	}
+	if fmt.Sprint(ids) != "[p2p-1 p2p-2 p2p-4 p2p-5]" {
+		t.Errorf("ListP2PTransactions() ids = %v", ids)
+	}
+	if meta := it.Meta(); meta.Page != 2 || meta.TotalCount != 4 {
+		t.Errorf("Meta() = %+v, want page 2 of 4 items", meta)
+	}
+
+	it = sdk.P2P.ListP2PTransactions(context.Background(), bovasdk.TransactionFilter{MerchantID: "missing"})
+	if it.Next() || it.Err() != nil {
+		t.Errorf("ListP2PTransactions(missing) returned items or error %v", it.Err())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it = sdk.P2P.ListP2PTransactions(ctx, bovasdk.TransactionFilter{PerPage: 2})
+	if !it.Next() {
+		t.Fatalf("ListP2PTransactions() error = %v", it.Err())
+	}
+	cancel()
+	for it.Next() {
+	}
+	if it.Err() != context.Canceled {
+		t.Errorf("Err() after cancel = %v, want context.Canceled", it.Err())
+	}
+}
+
+// TestListMassTransactions tests ListMassTransactions with a state filter
+func TestListMassTransactions(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+
+	for i := 1; i <= 3; i++ {
+		req := bovasdk.NewMassTransactionRequest("user-uuid", fmt.Sprintf("order-%d", i), "4111111111111111", "https://example.com/callback", 100, bovasdk.RUB, bovasdk.Card)
+		resp, err := sdk.MassTransaction.CreateMassTransaction(context.Background(), *req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 {
+			server.SetMassState(resp.Payload.ID, bovasdk.Successed)
+		}
+	}
+
+	it := sdk.MassTransaction.ListMassTransactions(context.Background(), bovasdk.TransactionFilter{State: bovasdk.WaitingPayment})
+	var merchantIDs []string
+	for it.Next() {
+		merchantIDs = append(merchantIDs, it.Item().MerchantId)
+	}
+	if it.Err() != nil || fmt.Sprint(merchantIDs) != "[order-1 order-3]" {
+		t.Errorf("ListMassTransactions() = %v, %v", merchantIDs, it.Err())
+	}
+}
+
+func isStatus(err error, status int) bool {
+	var apiErr *bovasdk.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
@@ -0,0 +1,88 @@
+package bovasdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// merchantIDRecoveryTimeout - максимальное время на поиск транзакции после неоднозначной ошибки создания.
+const merchantIDRecoveryTimeout = 10 * time.Second
+
+// ErrTransactionNotFound возвращается поиском по merchant_id, если API подтвердило, что транзакции нет:
+// первая страница списка с фильтром по merchant_id пуста. Для нее IsNotFound == true.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrLookupInconclusive возвращается поиском по merchant_id, если транзакция не найдена, но ее отсутствие
+// не подтверждено: API вернуло транзакции с другим merchant_id, то есть не применило фильтр, а просмотрена
+// только первая страница. Для нее IsNotFound == false, транзакция могла быть создана.
+var ErrLookupInconclusive = errors.New("transaction lookup inconclusive")
+
+// WithMerchantIDRecovery включает для методов создания поиск транзакции по merchant_id после неоднозначной ошибки
+// (сеть, таймаут, 5xx). Если транзакция найдена, она возвращается вместо ошибки и дубль не создается.
+// Поиск выполняется в рамках ctx вызова, поэтому если ошибка вызвана истечением ctx, поиска не будет,
+// для таких случаев используйте PayoutOutbox.
+func WithMerchantIDRecovery() CallOption {
+	return func(o *callOptions) {
+		o.recoverByMerchantID = true
+	}
+}
+
+// GetP2PTransactionByMerchantID ищет p2p транзакцию по нашему merchant_id. Просматривается только первая
+// страница списка с фильтром по merchant_id, поэтому если API не применило фильтр, возвращается ErrLookupInconclusive.
+func (p2p *P2P) GetP2PTransactionByMerchantID(ctx context.Context, merchantID string, opts ...CallOption) (*P2PTransactionResponse, error) {
+	it := p2p.ListP2PTransactions(ctx, TransactionFilter{MerchantID: merchantID}, opts...)
+	tx, err := findOnFirstPage(it, merchantID, func(tx P2PTransactionPayload) bool { return tx.MerchantID == merchantID })
+	if err != nil {
+		return nil, err
+	}
+	return &P2PTransactionResponse{ResultCode: "ok", Payload: tx}, nil
+}
+
+// GetMassTransactionByMerchantID ищет выплату по нашему merchant_id. Просматривается только первая
+// страница списка с фильтром по merchant_id, поэтому если API не применило фильтр, возвращается ErrLookupInconclusive.
+func (mt *MassTransaction) GetMassTransactionByMerchantID(ctx context.Context, merchantID string, opts ...CallOption) (*MassTransactionResponse, error) {
+	it := mt.ListMassTransactions(ctx, TransactionFilter{MerchantID: merchantID}, opts...)
+	tx, err := findOnFirstPage(it, merchantID, func(tx MassTransactionPayload) bool { return tx.MerchantId == merchantID })
+	if err != nil {
+		return nil, err
+	}
+	return &MassTransactionResponse{ResultCode: "ok", Payload: tx}, nil
+}
+
+// findOnFirstPage ищет элемент только на первой странице, чтобы не обходить всю историю,
+// если API проигнорировало фильтр. Отсутствие считается подтвержденным, только если весь список пуст.
+func findOnFirstPage[T any](it *ListIterator[T], merchantID string, match func(T) bool) (T, error) {
+	var zero T
+	items, meta, err := it.fetch(it.ctx, 1)
+	if err != nil {
+		return zero, err
+	}
+	for _, item := range items {
+		if match(item) {
+			return item, nil
+		}
+	}
+	if len(items) > 0 || meta.TotalCount > 0 || meta.TotalPages > 1 {
+		return zero, fmt.Errorf("%w: merchant_id %s", ErrLookupInconclusive, merchantID)
+	}
+	return zero, fmt.Errorf("%w: merchant_id %s", ErrTransactionNotFound, merchantID)
+}
+
+// recoverByMerchantID ищет транзакцию через lookup, если ошибка создания не означает однозначный отказ.
+// При неудачном поиске возвращается исходная ошибка.
+func recoverByMerchantID[T any](ctx context.Context, err error, opts []CallOption, merchantID string,
+	lookup func(ctx context.Context, merchantID string, opts ...CallOption) (*T, error)) (*T, error) {
+	if !newCallOptions(opts).recoverByMerchantID || isDefinitiveRejection(err) || merchantID == "" || ctx.Err() != nil {
+		return nil, err
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, merchantIDRecoveryTimeout)
+	defer cancel()
+	found, lookupErr := lookup(lookupCtx, merchantID)
+	if lookupErr != nil {
+		return nil, err
+	}
+	return found, nil
+}
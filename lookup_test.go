@@ -0,0 +1,123 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestGetMassTransactionByMerchantID tests lookup by merchant_id and recovery of lost create responses
+func TestGetMassTransactionByMerchantID(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+
+	// ответ на создание теряется после того, как сервер создал выплату
+	errLost := errors.New("connection reset")
+	logger, _ := bovasdk.NewLogger(false, "error")
+	sdk, err := bovasdk.NewBovaApiBuilder().
+		ApiURL(server.URL).
+		Secret(testSecret).
+		Logger(logger).
+		Middleware(bovasdk.AfterReceive(func(req *http.Request, resp *http.Response) error {
+			if req.Method == http.MethodPost {
+				return errLost
+			}
+			return nil
+		})).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	req := *bovasdk.NewMassTransactionRequest("user", "order-1", "4111111111111111", "https://example.com/callback", 500, bovasdk.RUB, bovasdk.Card)
+	if _, err = sdk.MassTransaction.CreateMassTransaction(ctx, req); err == nil {
+		t.Fatal("CreateMassTransaction() without recovery error = nil")
+	}
+
+	found, err := sdk.MassTransaction.GetMassTransactionByMerchantID(ctx, "order-1")
+	if err != nil || found.Payload.MerchantId != "order-1" {
+		t.Fatalf("GetMassTransactionByMerchantID() = %+v, %v", found, err)
+	}
+	if _, err = sdk.MassTransaction.GetMassTransactionByMerchantID(ctx, "order-2"); !bovasdk.IsNotFound(err) {
+		t.Errorf("GetMassTransactionByMerchantID(order-2) error = %v, want not found", err)
+	}
+
+	req.MerchantID = "order-2"
+	recovered, err := sdk.MassTransaction.CreateMassTransaction(ctx, req, bovasdk.WithMerchantIDRecovery())
+	if err != nil || recovered.Payload.MerchantId != "order-2" {
+		t.Fatalf("CreateMassTransaction() with recovery = %+v, %v", recovered, err)
+	}
+
+	p2pReq := bovasdk.P2PTransactionRequest{UserUUID: "user", MerchantID: "order-3", Amount: 100, CallbackURL: "https://example.com", Currency: bovasdk.RUB, PaymentMethod: bovasdk.Card}
+	p2p, err := sdk.P2P.CreateP2PTransaction(ctx, p2pReq, bovasdk.WithMerchantIDRecovery())
+	if err != nil || p2p.Payload.MerchantID != "order-3" || p2p.Payload.FormURL == "" {
+		t.Fatalf("CreateP2PTransaction() with recovery = %+v, %v", p2p, err)
+	}
+
+	it := sdk.MassTransaction.ListMassTransactions(ctx, bovasdk.TransactionFilter{})
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if it.Err() != nil || count != 2 {
+		t.Errorf("ListMassTransactions() count = %d, %v, want 2 without duplicates", count, it.Err())
+	}
+}
+
+// TestGetMassTransactionByMerchantIDUnfiltered tests that lookup reads a single page and reports an inconclusive result
+// when the API ignores the filter, and that recovery does not outlive the caller's ctx
+func TestGetMassTransactionByMerchantIDUnfiltered(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	for i := 0; i < 150; i++ {
+		var tx bovasdk.MassTransactionResponse
+		tx.Payload.ID, tx.Payload.MerchantId = fmt.Sprintf("mass-%d", i), fmt.Sprintf("order-%d", i)
+		server.AddMassTransaction(tx)
+	}
+
+	var lists int32
+	logger, _ := bovasdk.NewLogger(false, "error")
+	sdk, err := bovasdk.NewBovaApiBuilder().
+		ApiURL(server.URL).
+		Secret(testSecret).
+		Logger(logger).
+		Middleware(bovasdk.BeforeSend(func(req *http.Request) error {
+			if req.Method == http.MethodGet {
+				atomic.AddInt32(&lists, 1)
+				// API, которое не поддерживает фильтр по merchant_id
+				query := req.URL.Query()
+				query.Del("merchant_id")
+				req.URL.RawQuery = query.Encode()
+			}
+			return nil
+		})).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sdk.MassTransaction.GetMassTransactionByMerchantID(context.Background(), "missing")
+	if !errors.Is(err, bovasdk.ErrLookupInconclusive) || bovasdk.IsNotFound(err) {
+		t.Errorf("GetMassTransactionByMerchantID(missing) error = %v, want inconclusive", err)
+	}
+	if lists != 1 {
+		t.Errorf("GetMassTransactionByMerchantID(missing) requests = %d, want 1", lists)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := *bovasdk.NewMassTransactionRequest("user", "order-new", "4111111111111111", "https://example.com/callback", 500, bovasdk.RUB, bovasdk.Card)
+	if _, err = sdk.MassTransaction.CreateMassTransaction(ctx, req, bovasdk.WithMerchantIDRecovery()); !errors.Is(err, context.Canceled) {
+		t.Errorf("CreateMassTransaction() with canceled ctx error = %v, want context.Canceled", err)
+	}
+	if lists != 1 {
+		t.Errorf("recovery after canceled ctx sent %d lookup requests, want 0", lists-1)
+	}
+}
@@ -0,0 +1,227 @@
+package bovasdk
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sync"
+)
+
+// MultipartForm - тело запроса multipart/form-data для BovaApi.Do. Файлы передаются потоково.
+type MultipartForm struct {
+	fields []formField
+	files  []formFile
+}
+
+type formField struct {
+	name  string
+	value string
+}
+
+type formFile struct {
+	field string
+	file  *ProofImage
+}
+
+// NewMultipartForm создает пустую multipart форму.
+func NewMultipartForm() *MultipartForm {
+	return &MultipartForm{}
+}
+
+// WithField добавляет текстовое поле и возвращает обновленную форму
+func (f *MultipartForm) WithField(name, value string) *MultipartForm {
+	f.fields = append(f.fields, formField{name: name, value: value})
+	return f
+}
+
+// WithFile добавляет файл из r с именем filename и типом contentType и возвращает обновленную форму.
+// r читается один раз при отправке, SDK его не закрывает. Если r - *bytes.Reader, *strings.Reader
+// или *bytes.Buffer, размер тела известен заранее и оно отправляется не chunked.
+func (f *MultipartForm) WithFile(field, filename, contentType string, r io.Reader) *MultipartForm {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	size := int64(-1)
+	if l, ok := r.(interface{ Len() int }); ok {
+		size = int64(l.Len())
+	}
+	return f.WithProofImage(field, &ProofImage{Name: filename, ContentType: contentType, size: size, reader: r})
+}
+
+// WithProofImage добавляет проверенное изображение и возвращает обновленную форму
+func (f *MultipartForm) WithProofImage(field string, image *ProofImage) *MultipartForm {
+	f.files = append(f.files, formFile{field: field, file: image})
+	return f
+}
+
+// multipartBody формирует multipart тело запроса потоково, не буферизуя файлы в памяти.
+type multipartBody struct {
+	form     *MultipartForm
+	boundary string
+}
+
+func newMultipartBody(form *MultipartForm) *multipartBody {
+	return &multipartBody{form: form, boundary: multipart.NewWriter(io.Discard).Boundary()}
+}
+
+func (b *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// contentLength вычисляет длину тела без чтения файлов. Возвращает -1, если размер
+// хотя бы одного файла неизвестен, и тогда тело отправляется chunked.
+func (b *multipartBody) contentLength() int64 {
+	counter := &countingWriter{}
+	writer := multipart.NewWriter(counter)
+	if err := writer.SetBoundary(b.boundary); err != nil {
+		return -1
+	}
+
+	if err := b.writeFields(writer); err != nil {
+		return -1
+	}
+	var filesSize int64
+	for _, f := range b.form.files {
+		if f.file.size < 0 {
+			return -1
+		}
+		if _, err := writer.CreatePart(proofImageHeader(f.field, f.file)); err != nil {
+			return -1
+		}
+		filesSize += f.file.size
+	}
+	if err := writer.Close(); err != nil {
+		return -1
+	}
+
+	return counter.n + filesSize
+}
+
+// replayable возвращает true, если тело можно сформировать повторно, например для повторной отправки.
+func (b *multipartBody) replayable() bool {
+	for _, f := range b.form.files {
+		seekable := f.file.stream != nil && f.file.stream.seeker != nil
+		if f.file.data == nil && f.file.path == "" && !seekable {
+			return false
+		}
+	}
+	return true
+}
+
+// reader возвращает тело запроса. Горутина формирования тела запускается при первом чтении.
+// Если читатель закрыт до конца тела (например, транспорт вернул ошибку), горутина завершается.
+// Файлы из NewP2PDisputeRequest закрываются в любом случае: записанные - после записи, остальные -
+// при закрытии тела без чтения или при ошибке записи.
+func (b *multipartBody) reader() io.ReadCloser {
+	return &lazyPipeReader{body: b}
+}
+
+// setBody устанавливает тело в запрос. Горутина формирования тела запускается только при чтении запроса.
+func (b *multipartBody) setBody(httpReq *http.Request) {
+	httpReq.Body = b.reader()
+	if length := b.contentLength(); length >= 0 {
+		httpReq.ContentLength = length
+	}
+	if b.replayable() {
+		httpReq.GetBody = func() (io.ReadCloser, error) {
+			return b.reader(), nil
+		}
+	}
+	httpReq.Header.Set("Content-Type", b.contentType())
+}
+
+// closeFiles закрывает файлы, начиная с from, которые SDK должен закрыть после отправки.
+func (b *multipartBody) closeFiles(from int) {
+	for _, f := range b.form.files[from:] {
+		if f.file.closer != nil {
+			_ = f.file.closer.Close()
+		}
+	}
+}
+
+// lazyPipeReader запускает запись multipart тела в pipe при первом вызове Read.
+type lazyPipeReader struct {
+	body *multipartBody
+
+	mu     sync.Mutex
+	pr     *io.PipeReader
+	closed bool
+}
+
+func (l *lazyPipeReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	if l.pr == nil {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(l.body.writeTo(pw))
+		}()
+		l.pr = pr
+	}
+	pr := l.pr
+	l.mu.Unlock()
+	return pr.Read(p)
+}
+
+func (l *lazyPipeReader) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if l.pr == nil {
+		l.body.closeFiles(0)
+		return nil
+	}
+	// запись тела завершится ошибкой, и writeTo закроет еще не записанные файлы
+	return l.pr.Close()
+}
+
+// writeTo записывает тело в w. При ошибке файлы, которые еще не были записаны, закрываются.
+func (b *multipartBody) writeTo(w io.Writer) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(b.boundary); err != nil {
+		b.closeFiles(0)
+		return err
+	}
+
+	if err := b.writeFields(writer); err != nil {
+		b.closeFiles(0)
+		return err
+	}
+	for i, f := range b.form.files {
+		// writeProofImage закрывает свой файл и при ошибке
+		if err := writeProofImage(writer, f.field, f.file); err != nil {
+			b.closeFiles(i + 1)
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error closing writer: %v", err)
+	}
+	return nil
+}
+
+func (b *multipartBody) writeFields(writer *multipart.Writer) error {
+	for _, field := range b.form.fields {
+		if err := writer.WriteField(field.name, field.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,258 @@
+package bovasdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AlexanderMikhel/bva/card"
+)
+
+type OutboxStatus string
+
+const (
+	// OutboxPending - намерение записано, результат вызова API еще не получен.
+	OutboxPending OutboxStatus = "pending"
+	// OutboxSent - выплата создана в Bova.
+	OutboxSent OutboxStatus = "sent"
+	// OutboxFailed - Bova отклонила выплату, повторять ее нельзя без изменения запроса.
+	OutboxFailed OutboxStatus = "failed"
+	// OutboxUnknown - вызов завершился ошибкой сети или таймаутом, существует ли выплата, неизвестно.
+	OutboxUnknown OutboxStatus = "unknown"
+)
+
+// OutboxEntry - запись журнала выплат. Ключом записи является MerchantID запроса.
+// Пока выплата в статусе pending или unknown, Request хранится целиком, включая номер карты в to_card,
+// потому что он нужен для повторной отправки при восстановлении. После перехода в sent или failed
+// номер карты маскируется, но до этого хранилище журнала содержит номера карт и должно быть защищено.
+type OutboxEntry struct {
+	MerchantID    string                 `json:"merchant_id"`
+	Request       MassTransactionRequest `json:"request"`
+	Status        OutboxStatus           `json:"status"`
+	TransactionID string                 `json:"transaction_id,omitempty"`
+	State         TransactionStateEnum   `json:"state,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// OutboxStore хранит журнал выплат.
+type OutboxStore interface {
+	// Create атомарно добавляет запись, если записи с тем же MerchantID нет, и возвращает true.
+	// Если запись уже есть, она не меняется и возвращается false.
+	Create(ctx context.Context, entry OutboxEntry) (bool, error)
+	// Save заменяет запись с тем же MerchantID или создает ее. Запись в статусе sent
+	// не заменяется записью в другом статусе.
+	Save(ctx context.Context, entry OutboxEntry) error
+	// Get возвращает запись по MerchantID или nil, если записи нет.
+	Get(ctx context.Context, merchantID string) (*OutboxEntry, error)
+	// ListUnresolved возвращает записи в статусах pending и unknown.
+	ListUnresolved(ctx context.Context) ([]OutboxEntry, error)
+}
+
+// PayoutLookupFunc ищет выплату в Bova по merchant_id. Ошибку с ErrTransactionNotFound она должна возвращать,
+// только если подтверждено, что выплаты нет, тогда Recover отправит ее повторно. Любая другая ошибка,
+// в том числе ответ 404 или ErrLookupInconclusive, оставляет запись нерешенной.
+type PayoutLookupFunc func(ctx context.Context, merchantID string) (*MassTransactionResponse, error)
+
+// PayoutOutbox создает выплаты через журнал: намерение записывается до вызова API, результат - после.
+// Это позволяет после падения процесса восстановить состояние и не создать выплату дважды.
+type PayoutOutbox struct {
+	store  OutboxStore
+	mt     *MassTransaction
+	lookup PayoutLookupFunc
+	now    func() time.Time
+}
+
+// NewPayoutOutbox создает новый экземпляр PayoutOutbox.
+func NewPayoutOutbox(store OutboxStore, mt *MassTransaction) *PayoutOutbox {
+	return &PayoutOutbox{store: store, mt: mt, now: time.Now}
+}
+
+// WithLookup задает поиск выплаты по merchant_id для восстановления записей, по которым не получен ID транзакции,
+// и возвращает обновленный PayoutOutbox
+func (o *PayoutOutbox) WithLookup(lookup PayoutLookupFunc) *PayoutOutbox {
+	o.lookup = lookup
+	return o
+}
+
+// WithMerchantIDLookup использует для восстановления поиск выплаты по merchant_id в API Bova
+// и возвращает обновленный PayoutOutbox
+func (o *PayoutOutbox) WithMerchantIDLookup() *PayoutOutbox {
+	return o.WithLookup(func(ctx context.Context, merchantID string) (*MassTransactionResponse, error) {
+		return o.mt.GetMassTransactionByMerchantID(ctx, merchantID)
+	})
+}
+
+// ErrOutboxUnresolved возвращается, если по merchant_id есть нерешенная запись, которую не удалось восстановить.
+var ErrOutboxUnresolved = errors.New("outbox entry is unresolved")
+
+// Submit создает выплату не более одного раза для каждого merchant_id: API вызывается, только если
+// запись о намерении добавлена этим вызовом. Для уже отправленной или отклоненной выплаты возвращается
+// существующая запись без вызова API. Для нерешенной записи выплата ищется через поиск из WithLookup,
+// но повторно не отправляется, это делает Recover.
+func (o *PayoutOutbox) Submit(ctx context.Context, req MassTransactionRequest) (*OutboxEntry, error) {
+	now := o.now()
+	entry := OutboxEntry{MerchantID: req.MerchantID, Request: req, Status: OutboxPending, CreatedAt: now, UpdatedAt: now}
+	created, err := o.store.Create(ctx, entry)
+	if err != nil {
+		return nil, fmt.Errorf("error writing outbox: %v", err)
+	}
+	if created {
+		sent, callErr := o.send(ctx, entry)
+		if sent == nil || !isConflict(callErr) || o.lookup == nil {
+			return sent, callErr
+		}
+		// 409 может означать, что выплата с этим merchant_id уже создана, например прошлой попыткой
+		recovered, err := o.recoverEntry(ctx, *sent, false)
+		if err != nil {
+			return nil, err
+		}
+		if recovered.Status == OutboxSent {
+			return recovered, nil
+		}
+		return recovered, callErr
+	}
+
+	existing, err := o.store.Get(ctx, req.MerchantID)
+	if err != nil {
+		return nil, fmt.Errorf("error reading outbox: %v", err)
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("%w: merchant_id %s", ErrOutboxUnresolved, req.MerchantID)
+	}
+	if existing.Status == OutboxSent || existing.Status == OutboxFailed {
+		return existing, nil
+	}
+	recovered, err := o.recoverEntry(ctx, *existing, false)
+	if err != nil {
+		return nil, err
+	}
+	if recovered.Status != OutboxPending && recovered.Status != OutboxUnknown {
+		return recovered, nil
+	}
+	return nil, fmt.Errorf("%w: merchant_id %s", ErrOutboxUnresolved, req.MerchantID)
+}
+
+// send вызывает API и записывает результат.
+func (o *PayoutOutbox) send(ctx context.Context, entry OutboxEntry) (*OutboxEntry, error) {
+	resp, callErr := o.mt.CreateMassTransaction(ctx, entry.Request)
+	switch {
+	case callErr == nil:
+		entry.Status = OutboxSent
+		entry.TransactionID = resp.Payload.ID
+		entry.State = TransactionStateEnum(resp.Payload.State)
+		entry.Error = ""
+	case isDefinitiveRejection(callErr):
+		entry.Status = OutboxFailed
+		entry.Error = callErr.Error()
+	default:
+		entry.Status = OutboxUnknown
+		entry.Error = callErr.Error()
+	}
+	entry.UpdatedAt = o.now()
+
+	if err := o.save(ctx, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, callErr
+}
+
+// save записывает запись, маскируя номер карты, если запрос больше не будет отправляться.
+func (o *PayoutOutbox) save(ctx context.Context, entry *OutboxEntry) error {
+	if (entry.Status == OutboxSent || entry.Status == OutboxFailed) && !entry.Request.isPhonePayout() {
+		entry.Request.ToCard = card.Mask(entry.Request.ToCard)
+	}
+	if err := o.store.Save(ctx, *entry); err != nil {
+		return fmt.Errorf("error writing outbox: %v", err)
+	}
+	return nil
+}
+
+// Recover восстанавливает записи в статусах pending и unknown, например после перезапуска.
+// Если поиск из WithLookup подтвердил, что выплаты нет, она отправляется повторно, поэтому Recover нельзя вызывать,
+// пока для этих записей выполняется Submit. Возвращает записи, которые не удалось восстановить: их статус
+// unknown, а в Error причина, по которой выплату не удалось найти, их нужно разобрать вручную.
+func (o *PayoutOutbox) Recover(ctx context.Context) ([]OutboxEntry, error) {
+	entries, err := o.store.ListUnresolved(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading outbox: %v", err)
+	}
+
+	var unresolved []OutboxEntry
+	for _, entry := range entries {
+		recovered, err := o.recoverEntry(ctx, entry, true)
+		if err != nil {
+			return unresolved, err
+		}
+		if recovered.Status == OutboxPending || recovered.Status == OutboxUnknown {
+			unresolved = append(unresolved, *recovered)
+		}
+	}
+	return unresolved, nil
+}
+
+// recoverEntry ищет выплату записи в Bova. Если отсутствие выплаты подтверждено и resend равен true,
+// она отправляется повторно.
+func (o *PayoutOutbox) recoverEntry(ctx context.Context, entry OutboxEntry, resend bool) (*OutboxEntry, error) {
+	var resp *MassTransactionResponse
+	var err error
+	switch {
+	case entry.TransactionID != "":
+		resp, err = o.mt.GetMassTransaction(ctx, entry.TransactionID)
+	case o.lookup != nil:
+		resp, err = o.lookup(ctx, entry.MerchantID)
+		if errors.Is(err, ErrTransactionNotFound) && resend {
+			// выплата точно не была создана, ее можно безопасно отправить повторно
+			sent, sendErr := o.send(ctx, entry)
+			if sent == nil {
+				return nil, sendErr
+			}
+			return sent, nil
+		}
+	default:
+		return &entry, nil
+	}
+
+	if err != nil {
+		entry.Status = OutboxUnknown
+		entry.Error = err.Error()
+		entry.UpdatedAt = o.now()
+		if err = o.save(ctx, &entry); err != nil {
+			return nil, err
+		}
+		return &entry, nil
+	}
+
+	entry.Status = OutboxSent
+	entry.TransactionID = resp.Payload.ID
+	entry.State = TransactionStateEnum(resp.Payload.State)
+	entry.Error = ""
+	entry.UpdatedAt = o.now()
+	if err = o.save(ctx, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// isDefinitiveRejection возвращает true, если API однозначно отклонило запрос и выплата не создана.
+func isDefinitiveRejection(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	default:
+		return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+	}
+}
+
+// isConflict возвращает true для ответа 409: выплата с тем же merchant_id может уже существовать.
+func isConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
@@ -0,0 +1,179 @@
+package bovasdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// FileOutboxStore - журнал выплат в файле. Каждое изменение дописывается в конец файла
+// строкой JSON и синхронизируется на диск, при открытии журнал воспроизводится целиком.
+type FileOutboxStore struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	entries map[string]OutboxEntry
+}
+
+// NewFileOutboxStore открывает или создает файл журнала выплат.
+func NewFileOutboxStore(path string) (*FileOutboxStore, error) {
+	entries, err := readOutboxJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening outbox journal: %v", err)
+	}
+
+	return &FileOutboxStore{path: path, file: file, entries: entries}, nil
+}
+
+func readOutboxJournal(path string) (map[string]OutboxEntry, error) {
+	entries := make(map[string]OutboxEntry)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening outbox journal: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry OutboxEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// последняя строка может быть недописана при падении процесса
+			continue
+		}
+		entries[entry.MerchantID] = entry
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading outbox journal: %v", err)
+	}
+	return entries, nil
+}
+
+func (s *FileOutboxStore) Create(_ context.Context, entry OutboxEntry) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[entry.MerchantID]; ok {
+		return false, nil
+	}
+	if err := s.append(entry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *FileOutboxStore) Save(_ context.Context, entry OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.entries[entry.MerchantID]; ok && existing.Status == OutboxSent && entry.Status != OutboxSent {
+		return nil
+	}
+	return s.append(entry)
+}
+
+// append дописывает запись в журнал и обновляет состояние в памяти. Вызывается под s.mu.
+func (s *FileOutboxStore) append(entry OutboxEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err = s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err = s.file.Sync(); err != nil {
+		return err
+	}
+	s.entries[entry.MerchantID] = entry
+	return nil
+}
+
+func (s *FileOutboxStore) Get(_ context.Context, merchantID string) (*OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[merchantID]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (s *FileOutboxStore) ListUnresolved(_ context.Context) ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []OutboxEntry
+	for _, entry := range s.entries {
+		if entry.Status == OutboxPending || entry.Status == OutboxUnknown {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+// Compact перезаписывает журнал, оставляя только последнее состояние каждой записи.
+func (s *FileOutboxStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for _, entry := range s.entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		if _, err = writer.Write(append(data, '\n')); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, s.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	s.file.Close()
+	s.file = file
+	return nil
+}
+
+// Close закрывает файл журнала.
+func (s *FileOutboxStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
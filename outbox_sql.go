@@ -0,0 +1,172 @@
+package bovasdk
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SQLPlaceholder int
+
+const (
+	// QuestionPlaceholder - плейсхолдеры вида ? (MySQL, SQLite).
+	QuestionPlaceholder SQLPlaceholder = iota
+	// DollarPlaceholder - плейсхолдеры вида $1 (PostgreSQL).
+	DollarPlaceholder
+)
+
+// SQLOutboxStore - журнал выплат в SQL таблице. Драйвер базы данных подключает вызывающий код.
+type SQLOutboxStore struct {
+	db          *sql.DB
+	table       string
+	placeholder SQLPlaceholder
+}
+
+// NewSQLOutboxStore создает хранилище журнала выплат в таблице table.
+func NewSQLOutboxStore(db *sql.DB, table string, placeholder SQLPlaceholder) *SQLOutboxStore {
+	return &SQLOutboxStore{db: db, table: table, placeholder: placeholder}
+}
+
+// CreateTable создает таблицу журнала, если она еще не существует.
+func (s *SQLOutboxStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	merchant_id VARCHAR(255) PRIMARY KEY,
+	request TEXT NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	transaction_id VARCHAR(255) NOT NULL,
+	state VARCHAR(64) NOT NULL,
+	error TEXT NOT NULL,
+	created_at VARCHAR(64) NOT NULL,
+	updated_at VARCHAR(64) NOT NULL
+)`, s.table))
+	return err
+}
+
+// Create добавляет запись. Уникальность merchant_id обеспечивает первичный ключ таблицы: если вставка
+// не удалась и запись с этим merchant_id есть, значит ее добавил другой вызов, и возвращается false.
+func (s *SQLOutboxStore) Create(ctx context.Context, entry OutboxEntry) (bool, error) {
+	request, err := json.Marshal(entry.Request)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.db.ExecContext(ctx, s.rebind(fmt.Sprintf(
+		"INSERT INTO %s (merchant_id, request, status, transaction_id, state, error, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", s.table)),
+		entry.MerchantID, string(request), string(entry.Status), entry.TransactionID, string(entry.State), entry.Error,
+		formatOutboxTime(entry.CreatedAt), formatOutboxTime(entry.UpdatedAt))
+	if err == nil {
+		return true, nil
+	}
+	existing, getErr := s.Get(ctx, entry.MerchantID)
+	if getErr == nil && existing != nil {
+		return false, nil
+	}
+	return false, err
+}
+
+// Save обновляет запись одним UPDATE с условием на статус, чтобы не вернуть отправленную выплату в pending.
+func (s *SQLOutboxStore) Save(ctx context.Context, entry OutboxEntry) error {
+	request, err := json.Marshal(entry.Request)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE %s SET request = ?, status = ?, transaction_id = ?, state = ?, error = ?, updated_at = ? WHERE merchant_id = ?"
+	args := []interface{}{string(request), string(entry.Status), entry.TransactionID, string(entry.State), entry.Error,
+		formatOutboxTime(entry.UpdatedAt), entry.MerchantID}
+	if entry.Status != OutboxSent {
+		query += " AND status <> ?"
+		args = append(args, string(OutboxSent))
+	}
+	res, err := s.db.ExecContext(ctx, s.rebind(fmt.Sprintf(query, s.table)), args...)
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		// записи нет или она уже в статусе sent, во втором случае Create ничего не изменит
+		_, err = s.Create(ctx, entry)
+	}
+	return err
+}
+
+func (s *SQLOutboxStore) Get(ctx context.Context, merchantID string) (*OutboxEntry, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(fmt.Sprintf(
+		"SELECT merchant_id, request, status, transaction_id, state, error, created_at, updated_at FROM %s WHERE merchant_id = ?", s.table)),
+		merchantID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := scanOutboxEntries(rows)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+func (s *SQLOutboxStore) ListUnresolved(ctx context.Context) ([]OutboxEntry, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(fmt.Sprintf(
+		"SELECT merchant_id, request, status, transaction_id, state, error, created_at, updated_at FROM %s WHERE status IN (?, ?) ORDER BY created_at", s.table)),
+		string(OutboxPending), string(OutboxUnknown))
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboxEntries(rows)
+}
+
+func scanOutboxEntries(rows *sql.Rows) ([]OutboxEntry, error) {
+	defer rows.Close()
+
+	var entries []OutboxEntry
+	for rows.Next() {
+		var entry OutboxEntry
+		var request, status, state, createdAt, updatedAt string
+		if err := rows.Scan(&entry.MerchantID, &request, &status, &entry.TransactionID, &state, &entry.Error, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(request), &entry.Request); err != nil {
+			return nil, fmt.Errorf("error Unmarshal outbox request %s: %v", entry.MerchantID, err)
+		}
+		entry.Status = OutboxStatus(status)
+		entry.State = TransactionStateEnum(state)
+		var err error
+		if entry.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("error parsing outbox created_at %s: %v", entry.MerchantID, err)
+		}
+		if entry.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+			return nil, fmt.Errorf("error parsing outbox updated_at %s: %v", entry.MerchantID, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// rebind заменяет плейсхолдеры ? на диалект базы данных.
+func (s *SQLOutboxStore) rebind(query string) string {
+	if s.placeholder != DollarPlaceholder {
+		return query
+	}
+	var sb strings.Builder
+	n := 0
+	for _, ch := range query {
+		if ch == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
+
+// formatOutboxTime хранит время в UTC, чтобы сортировка по строке совпадала с сортировкой по времени.
+func formatOutboxTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000000Z07:00")
+}
@@ -0,0 +1,247 @@
+package bovasdk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOutboxDriver - in-memory драйвер, который понимает только запросы SQLOutboxStore и проверяет,
+// что плейсхолдеры в запросе соответствуют диалекту из DSN вида "question/имя" или "dollar/имя".
+type fakeOutboxDriver struct {
+	mu     sync.Mutex
+	tables map[string]map[string][]string
+}
+
+var outboxDriver = &fakeOutboxDriver{tables: make(map[string]map[string][]string)}
+
+func init() {
+	sql.Register("fake_outbox", outboxDriver)
+}
+
+func (d *fakeOutboxDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tables[dsn] == nil {
+		d.tables[dsn] = make(map[string][]string)
+	}
+	return &fakeOutboxConn{driver: d, dsn: dsn}, nil
+}
+
+type fakeOutboxConn struct {
+	driver *fakeOutboxDriver
+	dsn    string
+}
+
+func (c *fakeOutboxConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeOutboxStmt{conn: c, query: query}, nil
+}
+func (c *fakeOutboxConn) Close() error { return nil }
+func (c *fakeOutboxConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeOutboxStmt struct {
+	conn  *fakeOutboxConn
+	query string
+}
+
+func (s *fakeOutboxStmt) Close() error  { return nil }
+func (s *fakeOutboxStmt) NumInput() int { return -1 }
+
+var dollarPattern = regexp.MustCompile(`\$\d+`)
+
+// normalize проверяет плейсхолдеры диалекта и приводит запрос к виду с ?.
+func (s *fakeOutboxStmt) normalize(args []driver.Value) (string, error) {
+	if strings.HasPrefix(s.conn.dsn, "dollar/") {
+		if strings.Contains(s.query, "?") {
+			return "", fmt.Errorf("unexpected ? in postgres query: %s", s.query)
+		}
+		for i, p := range dollarPattern.FindAllString(s.query, -1) {
+			if p != fmt.Sprintf("$%d", i+1) {
+				return "", fmt.Errorf("placeholder %s at position %d: %s", p, i+1, s.query)
+			}
+		}
+	} else if dollarPattern.MatchString(s.query) {
+		return "", fmt.Errorf("unexpected $N in query: %s", s.query)
+	}
+	query := dollarPattern.ReplaceAllString(s.query, "?")
+	if n := strings.Count(query, "?"); n != len(args) {
+		return "", fmt.Errorf("query has %d placeholders, got %d args: %s", n, len(args), s.query)
+	}
+	return query, nil
+}
+
+func (s *fakeOutboxStmt) Exec(args []driver.Value) (driver.Result, error) {
+	query, err := s.normalize(args)
+	if err != nil {
+		return nil, err
+	}
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	table := d.tables[s.conn.dsn]
+
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(query, "INSERT INTO"):
+		id := args[0].(string)
+		if _, ok := table[id]; ok {
+			return nil, errors.New("UNIQUE constraint failed: merchant_id")
+		}
+		row := make([]string, len(args))
+		for i, a := range args {
+			row[i] = a.(string)
+		}
+		table[id] = row
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, "UPDATE"):
+		row, ok := table[args[6].(string)]
+		if !ok || (len(args) == 8 && row[2] == args[7].(string)) {
+			return driver.RowsAffected(0), nil
+		}
+		for i, col := range []int{1, 2, 3, 4, 5, 7} {
+			row[col] = args[i].(string)
+		}
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unexpected exec: %s", query)
+}
+
+func (s *fakeOutboxStmt) Query(args []driver.Value) (driver.Rows, error) {
+	query, err := s.normalize(args)
+	if err != nil {
+		return nil, err
+	}
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	table := d.tables[s.conn.dsn]
+
+	rows := &fakeOutboxRows{}
+	switch {
+	case strings.HasSuffix(query, "WHERE merchant_id = ?"):
+		if row, ok := table[args[0].(string)]; ok {
+			rows.data = append(rows.data, append([]string(nil), row...))
+		}
+	case strings.Contains(query, "WHERE status IN (?, ?)"):
+		for _, row := range table {
+			if row[2] == args[0].(string) || row[2] == args[1].(string) {
+				rows.data = append(rows.data, append([]string(nil), row...))
+			}
+		}
+		sort.Slice(rows.data, func(i, j int) bool { return rows.data[i][6] < rows.data[j][6] })
+	default:
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	return rows, nil
+}
+
+type fakeOutboxRows struct {
+	data [][]string
+}
+
+func (r *fakeOutboxRows) Columns() []string {
+	return []string{"merchant_id", "request", "status", "transaction_id", "state", "error", "created_at", "updated_at"}
+}
+func (r *fakeOutboxRows) Close() error { return nil }
+func (r *fakeOutboxRows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	for i, v := range r.data[0] {
+		dest[i] = v
+	}
+	r.data = r.data[1:]
+	return nil
+}
+
+// TestSQLOutboxStore tests the SQL store with both placeholder styles
+func TestSQLOutboxStore(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		placeholder SQLPlaceholder
+	}{
+		{"question", QuestionPlaceholder},
+		{"dollar", DollarPlaceholder},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn := tt.name + "/" + t.Name()
+			outboxDriver.mu.Lock()
+			delete(outboxDriver.tables, dsn)
+			outboxDriver.mu.Unlock()
+			db, err := sql.Open("fake_outbox", dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			store := NewSQLOutboxStore(db, "payout_outbox", tt.placeholder)
+			ctx := context.Background()
+			if err = store.CreateTable(ctx); err != nil {
+				t.Fatalf("CreateTable() error = %v", err)
+			}
+
+			now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+			req := *NewMassTransactionRequest(userUUID, "payout-1", "4111111111111111", "https://example.com/callback", 500, RUB, Card)
+			entry := OutboxEntry{MerchantID: "payout-1", Request: req, Status: OutboxPending, CreatedAt: now, UpdatedAt: now}
+			if created, err := store.Create(ctx, entry); err != nil || !created {
+				t.Fatalf("Create() = %v, %v, want true", created, err)
+			}
+			if created, err := store.Create(ctx, entry); err != nil || created {
+				t.Fatalf("Create() repeated = %v, %v, want false", created, err)
+			}
+
+			sent := entry
+			sent.Status, sent.TransactionID = OutboxSent, "mt-1"
+			if err = store.Save(ctx, sent); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			// устаревшая запись pending не возвращает выплату в статус pending
+			if err = store.Save(ctx, entry); err != nil {
+				t.Fatalf("Save() pending error = %v", err)
+			}
+			got, err := store.Get(ctx, "payout-1")
+			if err != nil || got == nil || got.Status != OutboxSent || got.TransactionID != "mt-1" || !got.CreatedAt.Equal(now) {
+				t.Errorf("Get() = %+v, %v", got, err)
+			}
+
+			other := entry
+			other.MerchantID, other.Status = "payout-2", OutboxUnknown
+			if err = store.Save(ctx, other); err != nil {
+				t.Fatalf("Save() new entry error = %v", err)
+			}
+			unresolved, err := store.ListUnresolved(ctx)
+			if err != nil || len(unresolved) != 1 || unresolved[0].MerchantID != "payout-2" {
+				t.Errorf("ListUnresolved() = %+v, %v", unresolved, err)
+			}
+
+			outboxDriver.mu.Lock()
+			outboxDriver.tables[dsn]["payout-2"][6] = "not a time"
+			outboxDriver.mu.Unlock()
+			if _, err = store.ListUnresolved(ctx); err == nil {
+				t.Errorf("ListUnresolved() with invalid created_at error = nil")
+			}
+		})
+	}
+}
+
+// TestSQLOutboxRebind tests placeholder rewriting for PostgreSQL
+func TestSQLOutboxRebind(t *testing.T) {
+	query := "UPDATE t SET a = ?, b = ? WHERE c = ?"
+	if got := (&SQLOutboxStore{placeholder: QuestionPlaceholder}).rebind(query); got != query {
+		t.Errorf("rebind() question = %q", got)
+	}
+	if got, want := (&SQLOutboxStore{placeholder: DollarPlaceholder}).rebind(query), "UPDATE t SET a = $1, b = $2 WHERE c = $3"; got != want {
+		t.Errorf("rebind() dollar = %q, want %q", got, want)
+	}
+}
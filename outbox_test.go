@@ -0,0 +1,172 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestPayoutOutbox tests idempotent submission and recovery after a crash
+func TestPayoutOutbox(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	store, err := bovasdk.NewFileOutboxStore(path)
+	if err != nil {
+		t.Fatalf("NewFileOutboxStore() error = %v", err)
+	}
+	outbox := bovasdk.NewPayoutOutbox(store, sdk.MassTransaction)
+
+	req := *bovasdk.NewMassTransactionRequest("user", "payout-1", "4111111111111111", "https://example.com/callback", 500, bovasdk.RUB, bovasdk.Card)
+	first, err := outbox.Submit(ctx, req)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	second, err := outbox.Submit(ctx, req)
+	if err != nil {
+		t.Fatalf("Submit() repeated error = %v", err)
+	}
+	if first.Status != bovasdk.OutboxSent || second.TransactionID != first.TransactionID {
+		t.Errorf("Submit() first = %+v, second = %+v", first, second)
+	}
+
+	// запись намерения без результата, как после падения процесса до ответа API
+	crashed := *bovasdk.NewMassTransactionRequest("user", "payout-2", "4111111111111111", "https://example.com/callback", 700, bovasdk.RUB, bovasdk.Card)
+	if err = store.Save(ctx, bovasdk.OutboxEntry{MerchantID: "payout-2", Request: crashed, Status: bovasdk.OutboxPending, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = bovasdk.NewFileOutboxStore(path)
+	if err != nil {
+		t.Fatalf("NewFileOutboxStore() reopen error = %v", err)
+	}
+	defer store.Close()
+
+	unresolved, err := bovasdk.NewPayoutOutbox(store, sdk.MassTransaction).Recover(ctx)
+	if err != nil || len(unresolved) != 1 || unresolved[0].MerchantID != "payout-2" {
+		t.Fatalf("Recover() without lookup = %+v, %v", unresolved, err)
+	}
+
+	// неподтвержденное отсутствие не должно приводить к повторной отправке
+	inconclusive := bovasdk.NewPayoutOutbox(store, sdk.MassTransaction).
+		WithLookup(func(ctx context.Context, merchantID string) (*bovasdk.MassTransactionResponse, error) {
+			return nil, fmt.Errorf("%w: merchant_id %s", bovasdk.ErrLookupInconclusive, merchantID)
+		})
+	unresolved, err = inconclusive.Recover(ctx)
+	if err != nil || len(unresolved) != 1 || unresolved[0].Status != bovasdk.OutboxUnknown || unresolved[0].TransactionID != "" {
+		t.Fatalf("Recover() with inconclusive lookup = %+v, %v", unresolved, err)
+	}
+
+	lookups := 0
+	outbox = bovasdk.NewPayoutOutbox(store, sdk.MassTransaction).
+		WithLookup(func(ctx context.Context, merchantID string) (*bovasdk.MassTransactionResponse, error) {
+			lookups++
+			return nil, fmt.Errorf("%w: merchant_id %s", bovasdk.ErrTransactionNotFound, merchantID)
+		})
+	if unresolved, err = outbox.Recover(ctx); err != nil || len(unresolved) != 0 {
+		t.Fatalf("Recover() = %+v, %v", unresolved, err)
+	}
+	recovered, err := store.Get(ctx, "payout-2")
+	if err != nil || recovered == nil || recovered.Status != bovasdk.OutboxSent || recovered.TransactionID == "" || lookups != 1 {
+		t.Errorf("Recover() entry = %+v, lookups = %d, err = %v", recovered, lookups, err)
+	}
+
+	persisted, err := store.Get(ctx, "payout-1")
+	if err != nil || persisted == nil || persisted.TransactionID != first.TransactionID {
+		t.Errorf("Get() after reopen = %+v, %v", persisted, err)
+	}
+}
+
+// TestPayoutOutboxConcurrentSubmit tests that concurrent submissions of one merchant_id create a single payout
+// and that the stored card number is masked once the payout is sent
+func TestPayoutOutboxConcurrentSubmit(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+	ctx := context.Background()
+
+	store, err := bovasdk.NewFileOutboxStore(filepath.Join(t.TempDir(), "outbox.jsonl"))
+	if err != nil {
+		t.Fatalf("NewFileOutboxStore() error = %v", err)
+	}
+	defer store.Close()
+	outbox := bovasdk.NewPayoutOutbox(store, sdk.MassTransaction)
+
+	req := *bovasdk.NewMassTransactionRequest("user", "payout-1", "4111111111111111", "https://example.com/callback", 500, bovasdk.RUB, bovasdk.Card)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := outbox.Submit(ctx, req); err != nil && !errors.Is(err, bovasdk.ErrOutboxUnresolved) {
+				t.Errorf("Submit() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	count := 0
+	it := sdk.MassTransaction.ListMassTransactions(ctx, bovasdk.TransactionFilter{MerchantID: "payout-1"})
+	for it.Next() {
+		count++
+	}
+	if it.Err() != nil || count != 1 {
+		t.Errorf("payouts created = %d, err = %v, want 1", count, it.Err())
+	}
+
+	entry, err := store.Get(ctx, "payout-1")
+	if err != nil || entry == nil || entry.Status != bovasdk.OutboxSent || entry.Request.ToCard != "411111******1111" {
+		t.Errorf("Get() = %+v, %v, want sent entry with masked card", entry, err)
+	}
+}
+
+// TestPayoutOutboxConflict tests that a 409 response is resolved by looking the payout up by merchant_id
+func TestPayoutOutboxConflict(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	// выплата создается, но ответ заменяется на 409, как при повторе уже принятого запроса
+	conflict := func(next bovasdk.Handler) bovasdk.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err == nil && req.Method == http.MethodPost {
+				resp.StatusCode = http.StatusConflict
+			}
+			return resp, err
+		}
+	}
+	logger, err := bovasdk.NewLogger(false, "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk, err := bovasdk.NewBovaApiBuilder().ApiURL(server.URL).Secret(testSecret).Logger(logger).Middleware(conflict).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	store, err := bovasdk.NewFileOutboxStore(filepath.Join(t.TempDir(), "outbox.jsonl"))
+	if err != nil {
+		t.Fatalf("NewFileOutboxStore() error = %v", err)
+	}
+	defer store.Close()
+
+	req := *bovasdk.NewMassTransactionRequest("user", "payout-1", "4111111111111111", "https://example.com/callback", 500, bovasdk.RUB, bovasdk.Card)
+	entry, err := bovasdk.NewPayoutOutbox(store, sdk.MassTransaction).WithMerchantIDLookup().Submit(ctx, req)
+	if err != nil || entry.Status != bovasdk.OutboxSent || entry.TransactionID == "" {
+		t.Errorf("Submit() = %+v, %v, want sent entry", entry, err)
+	}
+}
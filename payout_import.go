@@ -0,0 +1,387 @@
+package bovasdk
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/AlexanderMikhel/bva/card"
+)
+
+// PayoutColumnMapping задает названия колонок файла выплат для каждого поля MassTransactionRequest.
+// Пустое название означает, что колонки нет и используется значение по умолчанию из PayoutImporter.
+type PayoutColumnMapping struct {
+	ToCard             string
+	Amount             string
+	Currency           string
+	PaymentMethod      string
+	BankName           string
+	RecipientFirstName string
+	RecipientLastName  string
+	MerchantID         string
+}
+
+// DefaultPayoutColumnMapping возвращает маппинг колонок, принятый в файлах финансового отдела.
+func DefaultPayoutColumnMapping() PayoutColumnMapping {
+	return PayoutColumnMapping{
+		ToCard:             "to_card",
+		Amount:             "amount",
+		Currency:           "currency",
+		PaymentMethod:      "payment_method",
+		BankName:           "bank_name",
+		RecipientFirstName: "recipient_first_name",
+		RecipientLastName:  "recipient_last_name",
+		MerchantID:         "merchant_id",
+	}
+}
+
+// PayoutImporter разбирает CSV/XLSX файлы выплат в запросы MassTransactionRequest.
+type PayoutImporter struct {
+	userUUID             string
+	callbackURL          string
+	mapping              PayoutColumnMapping
+	defaultCurrency      CurrencyEnum
+	defaultPaymentMethod PaymentMethodEnum
+	merchantIDPrefix     string
+	comma                rune
+	checkCapabilities    bool
+}
+
+// NewPayoutImporter создает новый экземпляр PayoutImporter с обязательными параметрами.
+func NewPayoutImporter(userUUID, callbackURL string) *PayoutImporter {
+	return &PayoutImporter{
+		userUUID:         userUUID,
+		callbackURL:      callbackURL,
+		mapping:          DefaultPayoutColumnMapping(),
+		merchantIDPrefix: "payout-",
+		comma:            ',',
+	}
+}
+
+// WithMapping задает маппинг колонок и возвращает обновленный импортер
+func (i *PayoutImporter) WithMapping(mapping PayoutColumnMapping) *PayoutImporter {
+	i.mapping = mapping
+	return i
+}
+
+// WithDefaultCurrency задает валюту для строк без колонки валюты и возвращает обновленный импортер
+func (i *PayoutImporter) WithDefaultCurrency(currency CurrencyEnum) *PayoutImporter {
+	i.defaultCurrency = currency
+	return i
+}
+
+// WithDefaultPaymentMethod задает метод выплаты для строк без колонки метода и возвращает обновленный импортер
+func (i *PayoutImporter) WithDefaultPaymentMethod(paymentMethod PaymentMethodEnum) *PayoutImporter {
+	i.defaultPaymentMethod = paymentMethod
+	return i
+}
+
+// WithMerchantIDPrefix задает префикс merchant_id для строк без колонки merchant_id и возвращает обновленный импортер.
+// В этом случае merchant_id формируется как префикс + номер строки файла.
+func (i *PayoutImporter) WithMerchantIDPrefix(prefix string) *PayoutImporter {
+	i.merchantIDPrefix = prefix
+	return i
+}
+
+// WithComma задает разделитель колонок CSV и возвращает обновленный импортер
+func (i *PayoutImporter) WithComma(comma rune) *PayoutImporter {
+	i.comma = comma
+	return i
+}
+
+// WithCapabilityValidation включает проверку строк по таблице возможностей (MassTransactionRequest.ValidateCapability)
+// и возвращает обновленный импортер. По умолчанию строки проверяются только Validate.
+func (i *PayoutImporter) WithCapabilityValidation() *PayoutImporter {
+	i.checkCapabilities = true
+	return i
+}
+
+// PayoutImportRow представляет успешно разобранную строку файла выплат.
+type PayoutImportRow struct {
+	Line    int
+	Request MassTransactionRequest
+}
+
+// PayoutRowError описывает ошибку в конкретной строке файла выплат.
+type PayoutRowError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *PayoutRowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *PayoutRowError) Unwrap() error {
+	return e.Err
+}
+
+// PayoutImportResult содержит валидные запросы и отчет об ошибочных строках.
+type PayoutImportResult struct {
+	Rows   []PayoutImportRow
+	Errors []PayoutRowError
+}
+
+// Requests возвращает запросы на выплату из всех валидных строк.
+func (r *PayoutImportResult) Requests() []MassTransactionRequest {
+	requests := make([]MassTransactionRequest, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		requests = append(requests, row.Request)
+	}
+	return requests
+}
+
+// PayoutSubmitResult представляет результат отправки одной строки файла выплат.
+type PayoutSubmitResult struct {
+	Line     int
+	Request  MassTransactionRequest
+	Response *MassTransactionResponse
+	Err      error
+}
+
+// Submit последовательно создает выплаты по всем валидным строкам.
+// Ошибка одной выплаты не прерывает отправку остальных, отправка прерывается только при отмене ctx.
+func (r *PayoutImportResult) Submit(ctx context.Context, mt *MassTransaction) []PayoutSubmitResult {
+	results := make([]PayoutSubmitResult, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		if err := ctx.Err(); err != nil {
+			results = append(results, PayoutSubmitResult{Line: row.Line, Request: row.Request, Err: err})
+			continue
+		}
+		resp, err := mt.CreateMassTransaction(ctx, row.Request)
+		results = append(results, PayoutSubmitResult{Line: row.Line, Request: row.Request, Response: resp, Err: err})
+	}
+	return results
+}
+
+// ImportCSV разбирает CSV файл выплат. Первая строка файла должна содержать заголовки колонок.
+func (i *PayoutImporter) ImportCSV(r io.Reader) (*PayoutImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = i.comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading csv: %v", err)
+		}
+		// значение в кавычках может занимать несколько строк файла, поэтому номер строки берем у reader
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return i.importRecords(records, lines)
+}
+
+// ImportXLSX разбирает первый лист XLSX файла выплат. Первая строка листа должна содержать заголовки колонок.
+func (i *PayoutImporter) ImportXLSX(r io.ReaderAt, size int64) (*PayoutImportResult, error) {
+	records, err := readXLSXFirstSheet(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading xlsx: %v", err)
+	}
+	lines := make([]int, len(records))
+	for idx := range records {
+		lines[idx] = idx + 1
+	}
+	return i.importRecords(records, lines)
+}
+
+// importRecords разбирает записи файла, lines содержит номер строки файла для каждой записи.
+func (i *PayoutImporter) importRecords(records [][]string, lines []int) (*PayoutImportResult, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	header := make(map[string]int, len(records[0]))
+	for idx, name := range records[0] {
+		column := normalizeColumnName(name)
+		if column == "" {
+			continue
+		}
+		if _, ok := header[column]; ok {
+			return nil, fmt.Errorf("duplicate column %q in header", strings.TrimSpace(name))
+		}
+		header[column] = idx
+	}
+
+	required := []struct{ field, column string }{
+		{"to_card", i.mapping.ToCard},
+		{"amount", i.mapping.Amount},
+	}
+	for _, r := range required {
+		column := r.column
+		if column == "" {
+			return nil, fmt.Errorf("column mapping for %s is required", r.field)
+		}
+		if _, ok := header[normalizeColumnName(column)]; !ok {
+			return nil, fmt.Errorf("required column %q not found in header", column)
+		}
+	}
+
+	result := &PayoutImportResult{}
+	for idx, record := range records[1:] {
+		line := lines[idx+1]
+		if isBlankRecord(record) {
+			continue
+		}
+
+		req, rowErr := i.parseRecord(line, header, record)
+		if rowErr != nil {
+			result.Errors = append(result.Errors, *rowErr)
+			continue
+		}
+		result.Rows = append(result.Rows, PayoutImportRow{Line: line, Request: *req})
+	}
+
+	return result, nil
+}
+
+func (i *PayoutImporter) parseRecord(line int, header map[string]int, record []string) (*MassTransactionRequest, *PayoutRowError) {
+	value := func(column string) string {
+		if column == "" {
+			return ""
+		}
+		idx, ok := header[normalizeColumnName(column)]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	amount, err := parseImportAmount(value(i.mapping.Amount))
+	if err != nil {
+		return nil, &PayoutRowError{Line: line, Column: i.mapping.Amount, Err: err}
+	}
+
+	currency := i.defaultCurrency
+	if raw := value(i.mapping.Currency); raw != "" {
+		if currency, err = CurrencyFrom(strings.ToLower(raw)); err != nil {
+			return nil, &PayoutRowError{Line: line, Column: i.mapping.Currency, Err: err}
+		}
+	}
+
+	paymentMethod := i.defaultPaymentMethod
+	if raw := value(i.mapping.PaymentMethod); raw != "" {
+		if paymentMethod, err = PaymentMethodFrom(strings.ToLower(raw)); err != nil {
+			return nil, &PayoutRowError{Line: line, Column: i.mapping.PaymentMethod, Err: err}
+		}
+	}
+
+	merchantID := value(i.mapping.MerchantID)
+	if merchantID == "" {
+		merchantID = i.merchantIDPrefix + strconv.Itoa(line)
+	}
+
+	toCard := value(i.mapping.ToCard)
+	if paymentMethod == Card {
+		// в таблицах номер карты часто записан группами, например "4111 1111 1111 1111"
+		toCard = card.Normalize(toCard)
+	}
+	req := NewMassTransactionRequest(i.userUUID, merchantID, toCard, i.callbackURL, amount, currency, paymentMethod)
+
+	if bankName := value(i.mapping.BankName); bankName != "" {
+		if paymentMethod == Sbp || paymentMethod == SbpFast {
+			req.WithSbpBankName(bankName)
+		} else {
+			req.WithBankName(bankName)
+		}
+	}
+	if firstName := value(i.mapping.RecipientFirstName); firstName != "" {
+		req.WithRecipientFirstName(firstName)
+	}
+	if lastName := value(i.mapping.RecipientLastName); lastName != "" {
+		req.WithRecipientLastName(lastName)
+	}
+
+	if err = req.Validate(); err != nil {
+		return nil, &PayoutRowError{Line: line, Err: err}
+	}
+	if i.checkCapabilities {
+		if err = req.ValidateCapability(); err != nil {
+			return nil, &PayoutRowError{Line: line, Err: err}
+		}
+	}
+
+	return req, nil
+}
+
+// parseImportAmount разбирает целую сумму из таблицы. Пробелы считаются разделителями разрядов.
+// Если в сумме есть и запятая, и точка, последний из них - десятичный разделитель, например "1.500,00".
+// Один разделитель, за которым ровно 3 цифры, например "1,000", неоднозначен и отклоняется,
+// так как может означать и 1000, и 1.
+func parseImportAmount(raw string) (int, error) {
+	if raw == "" {
+		return 0, errors.New("amount is empty")
+	}
+
+	cleaned := strings.NewReplacer(" ", "", "\u00a0", "", "_", "").Replace(raw)
+
+	decimal := ""
+	switch comma, dot := strings.LastIndex(cleaned, ","), strings.LastIndex(cleaned, "."); {
+	case comma >= 0 && dot >= 0:
+		decimal = cleaned[max(comma, dot) : max(comma, dot)+1]
+	case comma >= 0 || dot >= 0:
+		sep := ","
+		if dot >= 0 {
+			sep = "."
+		}
+		if strings.Count(cleaned, sep) == 1 {
+			if _, frac, _ := strings.Cut(cleaned, sep); len(frac) == 3 {
+				return 0, fmt.Errorf("ambiguous amount %s: %q may be a thousands or a decimal separator", raw, sep)
+			}
+			decimal = sep
+		}
+	}
+
+	whole, frac := cleaned, ""
+	if decimal != "" {
+		idx := strings.LastIndex(cleaned, decimal)
+		whole, frac = cleaned[:idx], cleaned[idx+1:]
+	}
+	if strings.Trim(frac, "0") != "" {
+		return 0, fmt.Errorf("amount must be a whole number, got: %s", raw)
+	}
+
+	// оставшиеся запятые и точки - разделители разрядов, группы должны быть по 3 цифры
+	groups := strings.Split(strings.ReplaceAll(whole, ".", ","), ",")
+	if len(groups) > 1 {
+		for idx, g := range groups {
+			if (idx == 0 && (len(g) == 0 || len(g) > 3)) || (idx > 0 && len(g) != 3) {
+				return 0, fmt.Errorf("invalid amount: %s", raw)
+			}
+		}
+	}
+
+	amount, err := strconv.Atoi(strings.Join(groups, ""))
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount: %s", raw)
+	}
+	return amount, nil
+}
+
+func normalizeColumnName(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package bovasdk
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const payoutCSV = `to_card,amount,currency,payment_method,bank_name,recipient_first_name,recipient_last_name
+4111111111111111,"1 500",rub,card,,Иван,Иванов
+79161234567,2000.00,RUB,sbp,Сбербанк,Петр,Петров
+4111111111111111,abc,rub,card,,,
+,100,rub,card,,,
+4111111111111111,100,usd,card,,,
+`
+
+// TestImportCSV tests the ImportCSV method
+func TestImportCSV(t *testing.T) {
+	result, err := NewPayoutImporter(userUUID, "https://example.com/callback").
+		ImportCSV(strings.NewReader(payoutCSV))
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+
+	if len(result.Rows) != 2 {
+		t.Fatalf("ImportCSV() rows = %d, want 2", len(result.Rows))
+	}
+	if len(result.Errors) != 3 {
+		t.Fatalf("ImportCSV() errors = %d, want 3: %v", len(result.Errors), result.Errors)
+	}
+
+	card := result.Rows[0].Request
+	if card.Amount != 1500 || card.Currency != RUB || card.PaymentMethod != Card || card.MerchantID != "payout-2" {
+		t.Errorf("ImportCSV() first row = %+v", card)
+	}
+	if card.BankName != nil || card.RecipientFirstName == nil || *card.RecipientFirstName != "Иван" {
+		t.Errorf("ImportCSV() first row optional fields = %+v", card)
+	}
+
+	sbp := result.Rows[1].Request
+	if sbp.PaymentMethod != Sbp || sbp.SbpBankName == nil || *sbp.SbpBankName != "Сбербанк" || sbp.BankName != nil {
+		t.Errorf("ImportCSV() sbp row = %+v", sbp)
+	}
+
+	wantLines := []int{4, 5, 6}
+	for i, rowErr := range result.Errors {
+		if rowErr.Line != wantLines[i] {
+			t.Errorf("ImportCSV() error line = %d, want %d", rowErr.Line, wantLines[i])
+		}
+	}
+	if result.Errors[0].Column != "amount" {
+		t.Errorf("ImportCSV() error column = %q, want amount", result.Errors[0].Column)
+	}
+}
+
+// TestImportCSVCustomMapping tests column mapping and defaults
+func TestImportCSVCustomMapping(t *testing.T) {
+	data := "Карта;Сумма;Заказ\n4111111111111111;300;order-1\n"
+	result, err := NewPayoutImporter(userUUID, "https://example.com/callback").
+		WithComma(';').
+		WithMapping(PayoutColumnMapping{ToCard: "Карта", Amount: "Сумма", MerchantID: "Заказ"}).
+		WithDefaultCurrency(UZS).
+		WithDefaultPaymentMethod(Card).
+		ImportCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+	if len(result.Rows) != 1 {
+		t.Fatalf("ImportCSV() rows = %d, want 1, errors: %v", len(result.Rows), result.Errors)
+	}
+	req := result.Rows[0].Request
+	if req.MerchantID != "order-1" || req.Currency != UZS || req.Amount != 300 {
+		t.Errorf("ImportCSV() row = %+v", req)
+	}
+
+	if _, err = NewPayoutImporter(userUUID, "cb").ImportCSV(strings.NewReader("card,sum\n1,2\n")); err == nil {
+		t.Errorf("ImportCSV() expected error for missing required columns")
+	}
+}
+
+// TestImportXLSX tests the ImportXLSX method
+func TestImportXLSX(t *testing.T) {
+	data := buildXLSX(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>4111111111111111</t></is></c><c r="B3"><v>700</v></c><c r="C3" t="s"><v>3</v></c></row>
+<row r="4"><c r="A4"><v>4.111111111111111E15</v></c><c r="B4" t="n"><v>800</v></c><c r="C4" t="s"><v>3</v></c></row>`)
+
+	result, err := NewPayoutImporter(userUUID, "https://example.com/callback").
+		WithDefaultPaymentMethod(Card).
+		ImportXLSX(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ImportXLSX() error = %v", err)
+	}
+	if len(result.Rows) != 2 || len(result.Errors) != 0 {
+		t.Fatalf("ImportXLSX() rows = %d, errors = %v", len(result.Rows), result.Errors)
+	}
+	row := result.Rows[0]
+	if row.Line != 3 || row.Request.Amount != 700 || row.Request.ToCard != "4111111111111111" || row.Request.Currency != RUB {
+		t.Errorf("ImportXLSX() row = %+v", row)
+	}
+	if row = result.Rows[1]; row.Request.ToCard != "4111111111111111" || row.Request.Amount != 800 {
+		t.Errorf("ImportXLSX() numeric card row = %+v", row)
+	}
+
+	data = buildXLSX(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="1048576"><c r="A1048576"><v>1</v></c></row>`)
+	if _, err = NewPayoutImporter(userUUID, "https://example.com/callback").ImportXLSX(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Error("ImportXLSX() expected error for huge row gap")
+	}
+}
+
+// buildXLSX собирает XLSX файл с одним листом из строк sheetData.
+func buildXLSX(t *testing.T, rows string) []byte {
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Payouts" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/payouts.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>to_card</t></si><si><t>amount</t></si><si><r><t>cur</t></r><r><t>rency</t></r></si><si><t>rub</t></si></sst>`,
+		"xl/worksheets/payouts.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+` + rows + `
+</sheetData></worksheet>`,
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestParseImportAmount tests thousands and decimal separators in amounts
+func TestParseImportAmount(t *testing.T) {
+	for raw, want := range map[string]int{
+		"1 000":      1000,
+		"1\u00a0000": 1000,
+		"1.500,00":   1500,
+		"1,500.00":   1500,
+		"1,000,000":  1000000,
+		"2000.00":    2000,
+		"2000,0":     2000,
+		"700":        700,
+	} {
+		if got, err := parseImportAmount(raw); err != nil || got != want {
+			t.Errorf("parseImportAmount(%q) = %d, %v, want %d", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"1,000", "2.000", "1,5", "1,00,000", "1.000.000,50", "abc", ""} {
+		if got, err := parseImportAmount(raw); err == nil {
+			t.Errorf("parseImportAmount(%q) = %d, want error", raw, got)
+		}
+	}
+}
+
+// TestImportCSVLinesAndHeader tests line numbers of multiline quoted fields and duplicate header columns
+func TestImportCSVLinesAndHeader(t *testing.T) {
+	data := "to_card,amount,recipient_first_name\n4111111111111111,100,\"Иван\nИванович\"\n4111111111111111,abc,\n"
+	result, err := NewPayoutImporter(userUUID, "https://example.com/callback").
+		WithDefaultCurrency(RUB).WithDefaultPaymentMethod(Card).
+		ImportCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0].Line != 2 || len(result.Errors) != 1 || result.Errors[0].Line != 4 {
+		t.Errorf("ImportCSV() rows = %+v, errors = %+v, want lines 2 and 4", result.Rows, result.Errors)
+	}
+
+	_, err = NewPayoutImporter(userUUID, "https://example.com/callback").
+		ImportCSV(strings.NewReader("to_card,amount,Amount\n4111111111111111,100,200\n"))
+	if err == nil || !strings.Contains(err.Error(), "duplicate column") {
+		t.Errorf("ImportCSV() error = %v, want duplicate column", err)
+	}
+}
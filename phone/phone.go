@@ -0,0 +1,175 @@
+// Package phone приводит номера телефонов России, Узбекистана и Кореи к формату E.164
+// и к формату Bova (E.164 без "+"), в котором SDK отправляет номер для выплат по СБП. Этот формат
+// не описан в документации Bova и выведен из примеров, поэтому SDK отклоняет только невозможные номера
+// (ErrInvalidCharacters, ErrInvalidLength, ErrUnsupportedRegion), а номер с ErrNotMobile отправляет как есть.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Region - страна номера по ISO 3166-1.
+type Region string
+
+const (
+	RU Region = "RU"
+	UZ Region = "UZ"
+	KR Region = "KR"
+)
+
+var (
+	// ErrInvalidCharacters возвращается, если в номере есть буквы или другие символы, кроме цифр, "+", пробелов, скобок, точек и дефисов.
+	ErrInvalidCharacters = errors.New("phone number contains invalid characters")
+	// ErrInvalidLength возвращается, если число цифр не подходит для страны.
+	ErrInvalidLength = errors.New("phone number has invalid length")
+	// ErrUnsupportedRegion возвращается для кода страны, кроме +7, +998 и +82.
+	ErrUnsupportedRegion = errors.New("phone number region is not supported")
+	// ErrNotMobile возвращается для номера, который не может быть мобильным, например городского.
+	ErrNotMobile = errors.New("phone number is not a mobile number")
+)
+
+// region описывает правила номеров страны.
+type region struct {
+	countryCode string
+	// nationalLength - длина номера без кода страны и без внутреннего префикса
+	nationalLength []int
+	// trunkPrefix - внутренний префикс, который набирают внутри страны, например 8 в России и 0 в Корее
+	trunkPrefix string
+	// mobilePrefixes - допустимые начала национального номера мобильного телефона
+	mobilePrefixes []string
+}
+
+var regions = map[Region]region{
+	RU: {countryCode: "7", nationalLength: []int{10}, trunkPrefix: "8", mobilePrefixes: []string{"9"}},
+	UZ: {countryCode: "998", nationalLength: []int{9}, trunkPrefix: "8",
+		mobilePrefixes: []string{"20", "33", "50", "55", "77", "88", "90", "91", "93", "94", "95", "97", "98", "99"}},
+	KR: {countryCode: "82", nationalLength: []int{9, 10}, trunkPrefix: "0", mobilePrefixes: []string{"10", "11", "16", "17", "18", "19"}},
+}
+
+// Number - нормализованный мобильный номер.
+type Number struct {
+	Region Region
+	// CountryCode - код страны без "+", например "7"
+	CountryCode string
+	// National - номер без кода страны и внутреннего префикса, например "9161234567"
+	National string
+}
+
+// E164 возвращает номер в формате E.164, например "+79161234567".
+func (n Number) E164() string {
+	return "+" + n.Bova()
+}
+
+// Bova возвращает номер в формате Bova, например "79161234567".
+func (n Number) Bova() string {
+	return n.CountryCode + n.National
+}
+
+func (n Number) String() string {
+	return n.E164()
+}
+
+// Normalize разбирает мобильный номер в любом распространенном виде, например "8 (916) 123-45-67",
+// "+7 916 123 45 67" или "916 123 45 67". Номер с кодом страны (с "+" или без) определяет страну сам,
+// иначе он считается номером страны def.
+func Normalize(input string, def Region) (Number, error) {
+	digits, plus, err := clean(input)
+	if err != nil {
+		return Number{}, err
+	}
+	if digits == "" {
+		return Number{}, fmt.Errorf("%w: %q is empty", ErrInvalidLength, input)
+	}
+
+	if plus {
+		// со знаком "+" номер всегда начинается с кода страны
+		for _, r := range []Region{UZ, KR, RU} {
+			if strings.HasPrefix(digits, regions[r].countryCode) {
+				return national(input, r, strings.TrimPrefix(digits, regions[r].countryCode))
+			}
+		}
+		return Number{}, fmt.Errorf("%w: %q", ErrUnsupportedRegion, input)
+	}
+
+	rules, ok := regions[def]
+	if !ok {
+		return Number{}, fmt.Errorf("%w: %s", ErrUnsupportedRegion, def)
+	}
+	// номер страны def с внутренним префиксом или без него
+	if strings.HasPrefix(digits, rules.trunkPrefix) && hasLength(rules, len(digits)-len(rules.trunkPrefix)) {
+		return national(input, def, strings.TrimPrefix(digits, rules.trunkPrefix))
+	}
+	if hasLength(rules, len(digits)) {
+		return national(input, def, digits)
+	}
+	// номер с кодом страны, но без "+", например "79161234567" или "998901234567"
+	for _, r := range []Region{UZ, KR, RU} {
+		code := regions[r].countryCode
+		if strings.HasPrefix(digits, code) && len(digits) > len(code) {
+			if n, err := national(input, r, strings.TrimPrefix(digits, code)); err == nil {
+				return n, nil
+			}
+		}
+	}
+	return Number{}, fmt.Errorf("%w: %q has %d digits, expected %s number", ErrInvalidLength, input, len(digits), def)
+}
+
+// national проверяет номер без кода страны. В Корее внутренний префикс 0 иногда оставляют
+// и после кода страны, например "+82 010 1234 5678", поэтому он отбрасывается.
+func national(input string, r Region, digits string) (Number, error) {
+	rules := regions[r]
+	if r == KR && strings.HasPrefix(digits, rules.trunkPrefix) && hasLength(rules, len(digits)-1) {
+		digits = digits[1:]
+	}
+	if !hasLength(rules, len(digits)) {
+		return Number{}, fmt.Errorf("%w: %q has %d digits after +%s", ErrInvalidLength, input, len(digits), rules.countryCode)
+	}
+	mobile := false
+	for _, p := range rules.mobilePrefixes {
+		if strings.HasPrefix(digits, p) {
+			mobile = true
+			break
+		}
+	}
+	if !mobile {
+		return Number{}, fmt.Errorf("%w: %q", ErrNotMobile, input)
+	}
+	// корейские номера 010 всегда имеют 8 цифр абонента, короткие бывают только у старых кодов 011-019
+	if r == KR && strings.HasPrefix(digits, "10") && len(digits) != 10 {
+		return Number{}, fmt.Errorf("%w: %q has %d digits after +%s", ErrInvalidLength, input, len(digits), rules.countryCode)
+	}
+	return Number{Region: r, CountryCode: rules.countryCode, National: digits}, nil
+}
+
+func hasLength(rules region, n int) bool {
+	for _, l := range rules.nationalLength {
+		if l == n {
+			return true
+		}
+	}
+	return false
+}
+
+// clean оставляет только цифры и сообщает, начинался ли номер с "+" или международного префикса 00.
+func clean(input string) (digits string, plus bool, err error) {
+	s := strings.TrimSpace(input)
+	if strings.HasPrefix(s, "+") {
+		plus, s = true, s[1:]
+	} else if strings.HasPrefix(s, "00") {
+		plus, s = true, s[2:]
+	}
+
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.' || r == '\u00a0':
+		default:
+			return "", false, fmt.Errorf("%w: %q", ErrInvalidCharacters, input)
+		}
+	}
+	return sb.String(), plus, nil
+}
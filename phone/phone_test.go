@@ -0,0 +1,56 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestNormalize tests normalisation of RU, UZ and KR mobile numbers in common input forms
+func TestNormalize(t *testing.T) {
+	for _, c := range []struct {
+		input  string
+		region Region
+		want   string
+	}{
+		{"8 (916) 123-45-67", RU, "+79161234567"},
+		{"+7 916 123 45 67", RU, "+79161234567"},
+		{"+7916-123-4567", UZ, "+79161234567"},
+		{"79161234567", RU, "+79161234567"},
+		{"916 123 45 67", RU, "+79161234567"},
+		{"0079161234567", KR, "+79161234567"},
+		{"+998 90 123 45 67", RU, "+998901234567"},
+		{"998901234567", RU, "+998901234567"},
+		{"90 123-45-67", UZ, "+998901234567"},
+		{"8 90 1234567", UZ, "+998901234567"},
+		{"010-1234-5678", KR, "+821012345678"},
+		{"+82 10 1234 5678", RU, "+821012345678"},
+		{"+82 010 1234 5678", RU, "+821012345678"},
+		{"011-123-4567", KR, "+82111234567"},
+	} {
+		n, err := Normalize(c.input, c.region)
+		if err != nil || n.E164() != c.want {
+			t.Errorf("Normalize(%q, %s) = %s, %v, want %s", c.input, c.region, n, err, c.want)
+		}
+	}
+
+	n, _ := Normalize("8 (916) 123-45-67", RU)
+	if n.Bova() != "79161234567" || n.Region != RU {
+		t.Errorf("Bova() = %s, region %s", n.Bova(), n.Region)
+	}
+
+	for input, want := range map[string]error{
+		"":                  ErrInvalidLength,
+		"+7 916 123 45":     ErrInvalidLength,
+		"8 (495) 123-45-67": ErrNotMobile,
+		"+1 202 555 0100":   ErrUnsupportedRegion,
+		"916-CALL-NOW":      ErrInvalidCharacters,
+		"12345":             ErrInvalidLength,
+	} {
+		if _, err := Normalize(input, RU); !errors.Is(err, want) {
+			t.Errorf("Normalize(%q) error = %v, want %v", input, err, want)
+		}
+	}
+	if _, err := Normalize("010-1234-567", KR); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Normalize(010-1234-567, KR) error = %v, want invalid length", err)
+	}
+}
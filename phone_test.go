@@ -0,0 +1,58 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+	"github.com/AlexanderMikhel/bva/phone"
+)
+
+// TestSbpPayoutPhone tests that SBP payouts send the phone number in Bova format, reject impossible numbers
+// and keep numbers that are not recognized as mobile
+func TestSbpPayoutPhone(t *testing.T) {
+	req := bovasdk.NewMassTransactionRequest("user", "payout-1", "8 (916) 123-45-67", "https://example.com/callback", 1000, bovasdk.RUB, bovasdk.Sbp).
+		WithSbpBankName("Сбербанк")
+	if req.ToCard != "79161234567" {
+		t.Errorf("NewMassTransactionRequest() ToCard = %q, want 79161234567", req.ToCard)
+	}
+
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+
+	// запрос, заполненный без конструктора, нормализуется перед отправкой
+	req.ToCard = "+7 916 123 45 67"
+	resp, err := sdk.MassTransaction.CreateMassTransaction(context.Background(), *req)
+	if err != nil || resp.Payload.RecipientCard != "79161234567" {
+		t.Errorf("CreateMassTransaction() recipient = %+v, %v, want 79161234567", resp, err)
+	}
+
+	for number, want := range map[string]error{
+		"8 916 ABC-45-67":  phone.ErrInvalidCharacters,
+		"8 (916) 123-45":   phone.ErrInvalidLength,
+		"+1 212 555 01 23": phone.ErrUnsupportedRegion,
+	} {
+		req = bovasdk.NewMassTransactionRequest("user", "payout-3", number, "https://example.com/callback", 1000, bovasdk.RUB, bovasdk.Sbp).
+			WithSbpBankName("Сбербанк")
+		if err = req.Validate(); !errors.Is(err, want) {
+			t.Errorf("Validate(%q) error = %v, want %v", number, err, want)
+		}
+		if _, err = sdk.MassTransaction.CreateMassTransaction(context.Background(), *req); !errors.Is(err, want) {
+			t.Errorf("CreateMassTransaction(%q) error = %v, want %v", number, err, want)
+		}
+	}
+
+	// номер, который не похож на мобильный, отправляется как есть
+	req = bovasdk.NewMassTransactionRequest("user", "payout-2", "8 (495) 123-45-67", "https://example.com/callback", 1000, bovasdk.RUB, bovasdk.Sbp).
+		WithSbpBankName("Сбербанк")
+	if err = req.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want number left to the API", err)
+	}
+	resp, err = sdk.MassTransaction.CreateMassTransaction(context.Background(), *req)
+	if err != nil || resp.Payload.RecipientCard != "8 (495) 123-45-67" {
+		t.Errorf("CreateMassTransaction() recipient = %+v, %v, want original number", resp, err)
+	}
+}
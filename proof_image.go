@@ -0,0 +1,251 @@
+package bovasdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// sniffLen - количество байт, по которым http.DetectContentType определяет тип содержимого.
+const sniffLen = 512
+
+var (
+	ErrProofImageType     = errors.New("unsupported proof image type")
+	ErrProofImageTooLarge = errors.New("proof image is too large")
+)
+
+// defaultMaxProofImageSize - максимальный размер изображения для диспута по умолчанию в байтах.
+const defaultMaxProofImageSize int64 = 10 << 20
+
+// defaultProofImageTypes - типы содержимого, которые по умолчанию принимаются в качестве подтверждения оплаты.
+var defaultProofImageTypes = []string{"image/jpeg", "image/png", "image/webp", "application/pdf"}
+
+// ProofImageOption задает ограничения для конструкторов NewProofImageFrom*.
+type ProofImageOption func(*proofImageLimits)
+
+type proofImageLimits struct {
+	maxSize      int64
+	allowedTypes []string
+}
+
+// WithMaxProofImageSize задает максимальный размер изображения в байтах, по умолчанию 10 МБ.
+func WithMaxProofImageSize(size int64) ProofImageOption {
+	return func(l *proofImageLimits) {
+		l.maxSize = size
+	}
+}
+
+// WithProofImageTypes задает допустимые типы содержимого вместо jpeg, png, webp и pdf.
+func WithProofImageTypes(types ...string) ProofImageOption {
+	return func(l *proofImageLimits) {
+		l.allowedTypes = types
+	}
+}
+
+func newProofImageLimits(opts []ProofImageOption) proofImageLimits {
+	l := proofImageLimits{maxSize: defaultMaxProofImageSize, allowedTypes: defaultProofImageTypes}
+	for _, opt := range opts {
+		opt(&l)
+	}
+	return l
+}
+
+// ProofImage представляет изображение с подтверждением оплаты для диспута.
+// Изображения, созданные конструкторами NewProofImageFrom*, проверены по типу и размеру,
+// а переданные в них reader'ы SDK не закрывает.
+type ProofImage struct {
+	Name        string
+	ContentType string
+
+	size int64
+	// maxSize - ограничение размера при чтении, если размер заранее неизвестен
+	maxSize int64
+	data    []byte
+	stream  *proofStream
+	path    string
+	// reader задан для файлов из MultipartForm.WithFile и multipart.File из NewP2PDisputeRequest,
+	// closer - только для multipart.File, который закрывает SDK
+	reader io.Reader
+	closer io.Closer
+}
+
+// NewProofImageFromReader создает изображение из io.Reader. Размер проверяется во время чтения.
+// Если r реализует io.Seeker, изображение можно отправить повторно, иначе r читается один раз.
+func NewProofImageFromReader(name string, r io.Reader, opts ...ProofImageOption) (*ProofImage, error) {
+	limits := newProofImageLimits(opts)
+	stream := &proofStream{r: r}
+	if seeker, ok := r.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			stream.seeker, stream.start = seeker, start
+		}
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error reading proof image: %v", err)
+	}
+	stream.head = head[:n]
+
+	contentType, err := limits.detectType(stream.head)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProofImage{
+		Name:        filepath.Base(name),
+		ContentType: contentType,
+		size:        -1,
+		maxSize:     limits.maxSize,
+		stream:      stream,
+	}, nil
+}
+
+// NewProofImageFromBytes создает изображение из содержимого в памяти.
+func NewProofImageFromBytes(name string, data []byte, opts ...ProofImageOption) (*ProofImage, error) {
+	limits := newProofImageLimits(opts)
+	if int64(len(data)) > limits.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, max %d", ErrProofImageTooLarge, len(data), limits.maxSize)
+	}
+
+	contentType, err := limits.detectType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProofImage{Name: filepath.Base(name), ContentType: contentType, size: int64(len(data)), data: data}, nil
+}
+
+// NewProofImageFromPath создает изображение из файла. Файл открывается и закрывается при отправке диспута.
+func NewProofImageFromPath(path string, opts ...ProofImageOption) (*ProofImage, error) {
+	limits := newProofImageLimits(opts)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening proof image: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error reading proof image info: %v", err)
+	}
+	if info.Size() > limits.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, max %d", ErrProofImageTooLarge, info.Size(), limits.maxSize)
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error reading proof image: %v", err)
+	}
+
+	contentType, err := limits.detectType(head[:n])
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProofImage{Name: filepath.Base(path), ContentType: contentType, size: info.Size(), maxSize: limits.maxSize, path: path}, nil
+}
+
+// newProofImageFromMultipart сохраняет прежнее поведение NewP2PDisputeRequest: без проверок, файл закрывает SDK.
+func newProofImageFromMultipart(name string, file multipart.File) ProofImage {
+	return ProofImage{Name: name, ContentType: "application/octet-stream", size: -1, reader: file, closer: file}
+}
+
+// hasContent возвращает true, если у изображения есть источник содержимого.
+func (img *ProofImage) hasContent() bool {
+	return img.data != nil || img.path != "" || img.stream != nil || img.reader != nil
+}
+
+// open возвращает содержимое изображения для отправки и функцию освобождения ресурсов.
+func (img *ProofImage) open() (io.Reader, func() error, error) {
+	noop := func() error { return nil }
+
+	switch {
+	case img.data != nil:
+		return bytes.NewReader(img.data), noop, nil
+	case img.path != "":
+		file, err := os.Open(img.path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening proof image: %v", err)
+		}
+		return &maxSizeReader{r: file, remaining: img.maxSize, max: img.maxSize}, file.Close, nil
+	case img.stream != nil:
+		r, err := img.stream.open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading proof image %q: %v", img.Name, err)
+		}
+		return &maxSizeReader{r: r, remaining: img.maxSize, max: img.maxSize}, noop, nil
+	case img.reader != nil && img.closer != nil:
+		return img.reader, img.closer.Close, nil
+	case img.reader != nil:
+		return img.reader, noop, nil
+	default:
+		return nil, nil, fmt.Errorf("proof image %q has no content", img.Name)
+	}
+}
+
+func (l proofImageLimits) detectType(head []byte) (string, error) {
+	contentType := http.DetectContentType(head)
+	for _, allowed := range l.allowedTypes {
+		if contentType == allowed {
+			return contentType, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrProofImageType, contentType)
+}
+
+// proofStream - содержимое изображения из io.Reader. Прочитанные для определения типа байты
+// сохраняются в head, чтобы отправить изображение целиком.
+type proofStream struct {
+	mu   sync.Mutex
+	r    io.Reader
+	head []byte
+	// seeker задан, если r можно перемотать в начало изображения start для повторной отправки
+	seeker io.Seeker
+	start  int64
+	used   bool
+}
+
+func (s *proofStream) open() (io.Reader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seeker != nil {
+		if _, err := s.seeker.Seek(s.start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return s.r, nil
+	}
+	if s.used {
+		return nil, errors.New("reader has already been sent and does not implement io.Seeker")
+	}
+	s.used = true
+	return io.MultiReader(bytes.NewReader(s.head), s.r), nil
+}
+
+// maxSizeReader возвращает ErrProofImageTooLarge, если из reader'а прочитано больше допустимого.
+type maxSizeReader struct {
+	r         io.Reader
+	remaining int64
+	max       int64
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1]
+	}
+	n, err := m.r.Read(p)
+	m.remaining -= int64(n)
+	if m.remaining < 0 {
+		return n, fmt.Errorf("%w: max %d bytes", ErrProofImageTooLarge, m.max)
+	}
+	return n, err
+}
@@ -0,0 +1,160 @@
+package bovasdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type trackingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *trackingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+// TestProofImageValidation tests type and size validation of proof image constructors
+func TestProofImageValidation(t *testing.T) {
+	img, err := NewProofImageFromBytes("proof.png", pngHeader)
+	if err != nil {
+		t.Fatalf("NewProofImageFromBytes() error = %v", err)
+	}
+	if img.ContentType != "image/png" {
+		t.Errorf("NewProofImageFromBytes() content type = %s, want image/png", img.ContentType)
+	}
+
+	if _, err = NewProofImageFromBytes("proof.txt", []byte("plain text")); !errors.Is(err, ErrProofImageType) {
+		t.Errorf("NewProofImageFromBytes() error = %v, want ErrProofImageType", err)
+	}
+
+	if _, err = NewProofImageFromBytes("proof.png", pngHeader, WithProofImageTypes("image/jpeg")); !errors.Is(err, ErrProofImageType) {
+		t.Errorf("NewProofImageFromBytes() with jpeg only error = %v, want ErrProofImageType", err)
+	}
+
+	limit := WithMaxProofImageSize(int64(len(pngHeader)))
+	large := append(append([]byte{}, pngHeader...), 0)
+	if _, err = NewProofImageFromBytes("proof.png", large, limit); !errors.Is(err, ErrProofImageTooLarge) {
+		t.Errorf("NewProofImageFromBytes() error = %v, want ErrProofImageTooLarge", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "proof.png")
+	if err = os.WriteFile(path, large, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewProofImageFromPath(path, limit); !errors.Is(err, ErrProofImageTooLarge) {
+		t.Errorf("NewProofImageFromPath() error = %v, want ErrProofImageTooLarge", err)
+	}
+
+	img, err = NewProofImageFromReader("proof.png", bytes.NewReader(large), limit)
+	if err != nil {
+		t.Fatalf("NewProofImageFromReader() error = %v", err)
+	}
+	content, _, err := img.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.ReadAll(content); !errors.Is(err, ErrProofImageTooLarge) {
+		t.Errorf("reading proof image error = %v, want ErrProofImageTooLarge", err)
+	}
+}
+
+// TestCreateP2PDisputeWithReader tests that the uploaded image keeps its content type and the reader is not closed
+func TestCreateP2PDisputeWithReader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile(p2pDisputeProofImageForm)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		if header.Header.Get("Content-Type") != "image/png" || !bytes.Equal(data, pngHeader) {
+			http.Error(w, "unexpected file", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","data":{"id":1,"state":"opened"}}`))
+	}))
+	defer server.Close()
+
+	reader := &trackingReader{Reader: bytes.NewReader(pngHeader)}
+	img, err := NewProofImageFromReader("proof.png", reader)
+	if err != nil {
+		t.Fatalf("NewProofImageFromReader() error = %v", err)
+	}
+
+	p2p := p2pNew(newTransport(server.URL, NewEncoder("mock_api_secret"), server.Client()))
+	resp, err := p2p.CreateP2PDispute(context.Background(), NewP2PDisputeRequestWithImage("tx-1", 100, img))
+	if err != nil {
+		t.Fatalf("CreateP2PDispute() error = %v", err)
+	}
+	if resp.Data.State != DisputeOpened {
+		t.Errorf("CreateP2PDispute() state = %s, want %s", resp.Data.State, DisputeOpened)
+	}
+	if reader.closed {
+		t.Errorf("CreateP2PDispute() closed the caller's reader")
+	}
+}
+
+// TestProofImageFromReaderReplay tests that the sniffed bytes are sent and a seekable reader can be sent again
+func TestProofImageFromReaderReplay(t *testing.T) {
+	read := func(img *ProofImage) ([]byte, error) {
+		content, _, err := img.open()
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(content)
+	}
+
+	img, err := NewProofImageFromReader("proof.png", bytes.NewReader(pngHeader))
+	if err != nil {
+		t.Fatalf("NewProofImageFromReader() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if data, err := read(img); err != nil || !bytes.Equal(data, pngHeader) {
+			t.Errorf("open() #%d = %q, %v, want full image", i, data, err)
+		}
+	}
+
+	img, err = NewProofImageFromReader("proof.png", io.MultiReader(bytes.NewReader(pngHeader)))
+	if err != nil {
+		t.Fatalf("NewProofImageFromReader() error = %v", err)
+	}
+	if data, err := read(img); err != nil || !bytes.Equal(data, pngHeader) {
+		t.Errorf("open() = %q, %v, want full image", data, err)
+	}
+	if _, err = read(img); err == nil {
+		t.Errorf("second open() of non-seekable reader error = nil")
+	}
+}
+
+// TestCreateP2PDisputeWithoutImage tests that a nil image is reported as an error before sending and the files are closed
+func TestCreateP2PDisputeWithoutImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	p2p := p2pNew(newTransport(server.URL, NewEncoder("mock_api_secret"), server.Client()))
+	if _, err := p2p.CreateP2PDispute(context.Background(), NewP2PDisputeRequestWithImage("tx-1", 100, nil)); err == nil {
+		t.Errorf("CreateP2PDispute() with nil image error = nil")
+	}
+
+	// второй файл из NewP2PDisputeRequest закрывается, даже если запрос не прошел проверку
+	file := &trackingReader{Reader: bytes.NewReader(pngHeader)}
+	req := NewP2PDisputeRequestWithImage("tx-1", 100, nil)
+	req.WithProofImage2Image(&ProofImage{Name: "proof2.png", size: -1, reader: file, closer: file})
+	if _, err := p2p.CreateP2PDispute(context.Background(), req); err == nil || !file.closed {
+		t.Errorf("CreateP2PDispute() without first image error = %v, second file closed = %v", err, file.closed)
+	}
+}
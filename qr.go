@@ -0,0 +1,307 @@
+package bovasdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// QRFormat - формат изображения QR кода.
+type QRFormat string
+
+const (
+	QRFormatPNG QRFormat = "png"
+	QRFormatSVG QRFormat = "svg"
+)
+
+// QRTarget - ссылка транзакции, на которую указывает QR код.
+type QRTarget string
+
+const (
+	// QRTargetFormURL - платежная форма Bova (Payload.FormURL).
+	QRTargetFormURL QRTarget = "form_url"
+	// QRTargetSberpayURL - ссылка SberPay (Payload.RecipientCard.SberpayURL).
+	QRTargetSberpayURL QRTarget = "sberpay_url"
+)
+
+const (
+	defaultQRSize = 256
+	maxQRSize     = 2048
+	// qrQuietZone - ширина белой рамки в модулях, требуемая стандартом
+	qrQuietZone = 4
+)
+
+// ErrNoQRTarget возвращается, если у транзакции нет ссылки для QR кода, например SberpayURL для метода card.
+var ErrNoQRTarget = errors.New("transaction has no URL for QR code")
+
+// QROptions - параметры QR кода.
+type QROptions struct {
+	Format QRFormat
+	// Size - сторона изображения в пикселях вместе с белой рамкой
+	Size   int
+	Level  QRLevel
+	Target QRTarget
+}
+
+// NewQROptions создает параметры по умолчанию: PNG 256x256, уровень коррекции M, ссылка на платежную форму.
+func NewQROptions() *QROptions {
+	return &QROptions{Format: QRFormatPNG, Size: defaultQRSize, Level: QRLevelM, Target: QRTargetFormURL}
+}
+
+// WithFormat задает формат изображения и возвращает обновленные параметры
+func (o *QROptions) WithFormat(format QRFormat) *QROptions {
+	o.Format = format
+	return o
+}
+
+// WithSize задает сторону изображения в пикселях и возвращает обновленные параметры
+func (o *QROptions) WithSize(size int) *QROptions {
+	o.Size = size
+	return o
+}
+
+// WithLevel задает уровень коррекции ошибок и возвращает обновленные параметры
+func (o *QROptions) WithLevel(level QRLevel) *QROptions {
+	o.Level = level
+	return o
+}
+
+// WithTarget задает ссылку транзакции для QR кода и возвращает обновленные параметры
+func (o *QROptions) WithTarget(target QRTarget) *QROptions {
+	o.Target = target
+	return o
+}
+
+// ContentType возвращает MIME тип изображения.
+func (o *QROptions) ContentType() string {
+	if o.Format == QRFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+func (o *QROptions) validate() error {
+	switch o.Format {
+	case QRFormatPNG, QRFormatSVG:
+	default:
+		return fmt.Errorf("unsupported QR format: %q", o.Format)
+	}
+	if o.Size <= 0 || o.Size > maxQRSize {
+		return fmt.Errorf("QR size must be between 1 and %d, got %d", maxQRSize, o.Size)
+	}
+	return nil
+}
+
+// RenderQR кодирует content в QR код и возвращает изображение в формате из opts. Если opts равен nil,
+// используются параметры NewQROptions. Для PNG размер модуля округляется до целого числа пикселей,
+// но если модули не помещаются в Size, изображение будет больше Size.
+func RenderQR(content string, opts *QROptions) ([]byte, error) {
+	if opts == nil {
+		opts = NewQROptions()
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	code, err := encodeQR([]byte(content), opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Format == QRFormatSVG {
+		return code.svg(opts.Size), nil
+	}
+	return code.png(opts.Size)
+}
+
+// QRCode возвращает QR код со ссылкой транзакции из opts.Target.
+func (p *P2PTransactionPayload) QRCode(opts *QROptions) ([]byte, error) {
+	if opts == nil {
+		opts = NewQROptions()
+	}
+	url, err := p.qrURL(opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	return RenderQR(url, opts)
+}
+
+func (p *P2PTransactionPayload) qrURL(target QRTarget) (string, error) {
+	var url string
+	switch target {
+	case QRTargetFormURL, "":
+		url = p.FormURL
+	case QRTargetSberpayURL:
+		url = p.RecipientCard.SberpayURL
+	default:
+		return "", fmt.Errorf("unsupported QR target: %q", target)
+	}
+	if url == "" {
+		return "", fmt.Errorf("%w: %s is empty for %s", ErrNoQRTarget, target, p.ID)
+	}
+	return url, nil
+}
+
+func (q *qrCode) png(size int) ([]byte, error) {
+	modules := q.size + 2*qrQuietZone
+	scale := max(size/modules, 1)
+	side := max(size, modules*scale)
+	offset := (side-modules*scale)/2 + qrQuietZone*scale
+
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if !q.dark(x, y) {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[(offset+y*scale+dy)*img.Stride:]
+				for dx := 0; dx < scale; dx++ {
+					row[offset+x*scale+dx] = 1
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("error encoding QR png: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// svg рисует темные модули одним path, изображение масштабируется до size без потери четкости.
+func (q *qrCode) svg(size int) []byte {
+	modules := q.size + 2*qrQuietZone
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, modules, modules)
+	sb.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/><path fill="#000000" d="`)
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.dark(x, y) {
+				fmt.Fprintf(&sb, "M%d %dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+	sb.WriteString(`"/></svg>`)
+	return []byte(sb.String())
+}
+
+// QRHandler отдает QR код p2p транзакции по ее id: последний сегмент пути или параметр id,
+// например GET /qr/{id}?format=svg&size=512&target=sberpay_url&level=H.
+type QRHandler struct {
+	get  func(ctx context.Context, transactionID string) (*P2PTransactionResponse, error)
+	opts QROptions
+}
+
+// NewQRHandler создает http.Handler для QR кодов с параметрами по умолчанию opts, которые можно переопределить в query.
+func NewQRHandler(api *BovaApi, opts *QROptions) *QRHandler {
+	if opts == nil {
+		opts = NewQROptions()
+	}
+	return &QRHandler{
+		get: func(ctx context.Context, transactionID string) (*P2PTransactionResponse, error) {
+			return api.P2P.GetP2PTransaction(ctx, transactionID)
+		},
+		opts: *opts,
+	}
+}
+
+// WithCache получает транзакции через кэш и возвращает обновленный обработчик
+func (h *QRHandler) WithCache(cache *TransactionCache) *QRHandler {
+	h.get = cache.GetP2PTransaction
+	return h
+}
+
+func (h *QRHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	id := query.Get("id")
+	if id == "" {
+		id = path.Base(r.URL.Path)
+	}
+	if id == "" || id == "/" || id == "." {
+		http.Error(w, "transaction id is required", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := h.queryOptions(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.get(r.Context(), id)
+	if err != nil {
+		if IsNotFound(err) {
+			http.Error(w, "transaction not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "error getting transaction", http.StatusBadGateway)
+		return
+	}
+
+	body, err := resp.Payload.QRCode(opts)
+	if err != nil {
+		if errors.Is(err, ErrNoQRTarget) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "error rendering QR code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	// ссылка транзакции не меняется, но транзакция может истечь, поэтому кэшируем ненадолго
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
+
+func (h *QRHandler) queryOptions(query map[string][]string) (*QROptions, error) {
+	opts := h.opts
+	get := func(key string) string {
+		if v := query[key]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	if v := get("format"); v != "" {
+		opts.Format = QRFormat(strings.ToLower(v))
+	}
+	if v := get("target"); v != "" {
+		opts.Target = QRTarget(v)
+		if opts.Target != QRTargetFormURL && opts.Target != QRTargetSberpayURL {
+			return nil, fmt.Errorf("unsupported QR target: %q", v)
+		}
+	}
+	if v := get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size: %q", v)
+		}
+		opts.Size = size
+	}
+	if v := get("level"); v != "" {
+		level := strings.Index("LMQH", strings.ToUpper(v))
+		if len(v) != 1 || level < 0 {
+			return nil, fmt.Errorf("invalid level: %q", v)
+		}
+		opts.Level = QRLevel(level)
+	}
+	return &opts, opts.validate()
+}
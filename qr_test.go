@@ -0,0 +1,75 @@
+package bovasdk_test
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestQRHandler tests QR code rendering and QRHandler against the mock server
+func TestQRHandler(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+
+	req := bovasdk.P2PTransactionRequest{UserUUID: "user", MerchantID: "order-1", Amount: 100, CallbackURL: "https://example.com", Currency: bovasdk.RUB, PaymentMethod: bovasdk.Card}
+	p2p, err := sdk.P2P.CreateP2PTransaction(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := httptest.NewServer(http.StripPrefix("/qr", bovasdk.NewQRHandler(sdk, bovasdk.NewQROptions().WithSize(300))))
+	defer handler.Close()
+
+	resp, err := http.Get(handler.URL + "/qr/" + p2p.Payload.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(resp.Body)
+	resp.Body.Close()
+	if err != nil || resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("GET png = %s, %v", resp.Header.Get("Content-Type"), err)
+	}
+	if b := img.Bounds(); b.Dx() != 300 || b.Dy() != 300 {
+		t.Errorf("png size = %v, want 300x300", b)
+	}
+
+	resp, err = http.Get(handler.URL + "/qr?id=" + p2p.Payload.ID + "&format=svg&level=H")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svg bytes.Buffer
+	_, _ = svg.ReadFrom(resp.Body)
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "image/svg+xml" || !strings.HasPrefix(svg.String(), "<svg") {
+		t.Errorf("GET svg = %s %q", resp.Header.Get("Content-Type"), svg.String())
+	}
+
+	for query, status := range map[string]int{
+		"/qr/unknown": http.StatusNotFound,
+		"/qr/" + p2p.Payload.ID + "?target=sberpay_url": http.StatusNotFound,
+		"/qr/" + p2p.Payload.ID + "?size=0":             http.StatusBadRequest,
+		"/qr/" + p2p.Payload.ID + "?level=X":            http.StatusBadRequest,
+		"/qr/" + p2p.Payload.ID + "?format=gif":         http.StatusBadRequest,
+	} {
+		resp, err = http.Get(handler.URL + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("GET %s = %d, want %d", query, resp.StatusCode, status)
+		}
+	}
+
+	if _, err = bovasdk.RenderQR(strings.Repeat("a", 3000), nil); err == nil {
+		t.Error("RenderQR() expected error for too long content")
+	}
+}
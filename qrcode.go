@@ -0,0 +1,535 @@
+package bovasdk
+
+import (
+	"fmt"
+)
+
+// Минимальный кодировщик QR кодов (ISO/IEC 18004): только байтовый режим, версии 1-40,
+// все уровни коррекции ошибок и автоматический выбор маски, чего достаточно для ссылок на оплату.
+
+// QRLevel - уровень коррекции ошибок QR кода.
+type QRLevel int
+
+const (
+	// QRLevelL восстанавливает около 7% поврежденных данных.
+	QRLevelL QRLevel = iota
+	// QRLevelM восстанавливает около 15% поврежденных данных.
+	QRLevelM
+	// QRLevelQ восстанавливает около 25% поврежденных данных.
+	QRLevelQ
+	// QRLevelH восстанавливает около 30% поврежденных данных.
+	QRLevelH
+)
+
+func (l QRLevel) String() string {
+	switch l {
+	case QRLevelL:
+		return "L"
+	case QRLevelM:
+		return "M"
+	case QRLevelQ:
+		return "Q"
+	case QRLevelH:
+		return "H"
+	}
+	return fmt.Sprintf("QRLevel(%d)", int(l))
+}
+
+// formatBits - биты уровня в информации о формате.
+func (l QRLevel) formatBits() int {
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+// qrECCPerBlock - число байт коррекции в блоке по уровню и версии.
+var qrECCPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// qrECCBlocks - число блоков коррекции по уровню и версии.
+var qrECCBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+const (
+	qrMinVersion = 1
+	qrMaxVersion = 40
+)
+
+// qrCode - матрица модулей QR кода, true - темный модуль.
+type qrCode struct {
+	version  int
+	size     int
+	level    QRLevel
+	modules  [][]bool
+	function [][]bool
+}
+
+// encodeQR кодирует data в QR код минимальной подходящей версии с маской с наименьшим штрафом.
+// Способ подсчета штрафа у кодировщиков различается, поэтому маска может не совпасть с выбранной
+// другой библиотекой, для сканера это не важно: маска записывается в информацию о формате.
+func encodeQR(data []byte, level QRLevel) (*qrCode, error) {
+	return encodeQRMask(data, level, -1)
+}
+
+// encodeQRMask кодирует data с маской mask, при mask < 0 маска выбирается по штрафу.
+func encodeQRMask(data []byte, level QRLevel, mask int) (*qrCode, error) {
+	if level < QRLevelL || level > QRLevelH {
+		return nil, fmt.Errorf("invalid QR error correction level: %v", level)
+	}
+
+	version := qrMinVersion
+	for ; ; version++ {
+		if version > qrMaxVersion {
+			return nil, fmt.Errorf("data is too long for QR code: %d bytes at level %v", len(data), level)
+		}
+		if qrSegmentBits(len(data), version) <= qrDataCodewords(version, level)*8 {
+			break
+		}
+	}
+
+	q := newQRCode(version, level)
+	q.drawFunctionPatterns()
+	q.drawCodewords(q.addECCAndInterleave(qrDataBytes(data, version, level)))
+
+	if mask >= 0 {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		return q, nil
+	}
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // маска обратима
+	}
+	q.applyMask(best)
+	q.drawFormatBits(best)
+	return q, nil
+}
+
+// qrDataBytes возвращает кодовые слова данных: режим, длина, данные, терминатор и байты дополнения.
+func qrDataBytes(data []byte, version int, level QRLevel) []byte {
+	bits := &qrBitBuffer{}
+	bits.append(0x4, 4) // байтовый режим
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := qrDataCodewords(version, level) * 8
+	bits.append(0, min(4, capacity-bits.len()))
+	bits.append(0, (8-bits.len()%8)%8)
+	for pad := 0xEC; bits.len() < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	return bits.bytes()
+}
+
+func newQRCode(version int, level QRLevel) *qrCode {
+	size := version*4 + 17
+	q := &qrCode{version: version, size: size, level: level}
+	q.modules = make([][]bool, size)
+	q.function = make([][]bool, size)
+	for i := range q.modules {
+		q.modules[i] = make([]bool, size)
+		q.function[i] = make([]bool, size)
+	}
+	return q
+}
+
+// dark возвращает цвет модуля в столбце x и строке y.
+func (q *qrCode) dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// qrCountBits - длина поля количества байт для версии.
+func qrCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+func qrSegmentBits(n, version int) int {
+	return 4 + qrCountBits(version) + n*8
+}
+
+// qrRawModules - число модулей версии, доступных для данных и коррекции.
+func qrRawModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func qrDataCodewords(version int, level QRLevel) int {
+	return qrRawModules(version)/8 - qrECCPerBlock[level][version]*qrECCBlocks[level][version]
+}
+
+// qrAlignmentPositions возвращает координаты центров выравнивающих узоров.
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+func (q *qrCode) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+func (q *qrCode) drawFunctionPatterns() {
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinderPattern(3, 3)
+	q.drawFinderPattern(q.size-4, 3)
+	q.drawFinderPattern(3, q.size-4)
+
+	positions := qrAlignmentPositions(q.version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// узоры не рисуются поверх поисковых
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			q.drawAlignmentPattern(x, y)
+		}
+	}
+
+	q.drawFormatBits(0) // резервируем место, биты перерисовываются после выбора маски
+	q.drawVersion()
+}
+
+func (q *qrCode) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.size || yy < 0 || yy >= q.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			q.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (q *qrCode) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// qrFormatBits возвращает 15 бит информации о формате с кодом БЧХ и маской.
+func qrFormatBits(level QRLevel, mask int) int {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (q *qrCode) drawFormatBits(mask int) {
+	bits := qrFormatBits(q.level, mask)
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(bits, i))
+	}
+	q.setFunction(8, 7, bit(bits, 6))
+	q.setFunction(8, 8, bit(bits, 7))
+	q.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(bits, i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// qrVersionBits возвращает 18 бит информации о версии с кодом Голея.
+func qrVersionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+func (q *qrCode) drawVersion() {
+	if q.version < 7 {
+		return
+	}
+	bits := qrVersionBits(q.version)
+	for i := 0; i < 18; i++ {
+		a, b := q.size-11+i%3, i/3
+		q.setFunction(a, b, bit(bits, i))
+		q.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// addECCAndInterleave делит данные на блоки, добавляет к ним коды Рида-Соломона и перемежает байты блоков.
+func (q *qrCode) addECCAndInterleave(data []byte) []byte {
+	numBlocks := qrECCBlocks[q.level][q.version]
+	eccLen := qrECCPerBlock[q.level][q.version]
+	raw := qrRawModules(q.version) / 8
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+
+	divisor := reedSolomonDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortLen - eccLen
+		if i >= numShort {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := reedSolomonRemainder(block, divisor)
+		if i < numShort {
+			block = append(block, 0) // выравнивание с длинными блоками, при перемежении пропускается
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, raw)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// drawCodewords размещает байты зигзагом по парам столбцов справа налево.
+func (q *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !q.function[y][x] && i < len(data)*8 {
+					q.modules[y][x] = bit(int(data[i>>3]), 7-i&7)
+					i++
+				}
+			}
+		}
+	}
+}
+
+func qrMask(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+func (q *qrCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if !q.function[y][x] && qrMask(mask, x, y) {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+var (
+	qrFinderLike     = []bool{true, false, true, true, true, false, true, false, false, false, false}
+	qrFinderLikeBack = []bool{false, false, false, false, true, false, true, true, true, false, true}
+)
+
+// penalty оценивает маску по четырем правилам стандарта, меньше - лучше.
+func (q *qrCode) penalty() int {
+	result := 0
+	line := make([]bool, q.size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < q.size; i++ {
+			for j := 0; j < q.size; j++ {
+				if vertical {
+					line[j] = q.modules[j][i]
+				} else {
+					line[j] = q.modules[i][j]
+				}
+			}
+
+			// серии одного цвета длиной от 5 модулей
+			run := 1
+			for j := 1; j <= q.size; j++ {
+				if j < q.size && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += 3 + run - 5
+				}
+				run = 1
+			}
+
+			// узоры, похожие на поисковые
+			for j := 0; j+len(qrFinderLike) <= q.size; j++ {
+				if boolsEqual(line[j:j+len(qrFinderLike)], qrFinderLike) || boolsEqual(line[j:j+len(qrFinderLike)], qrFinderLikeBack) {
+					result += 40
+				}
+			}
+		}
+	}
+
+	// квадраты 2x2 одного цвета
+	dark := 0
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			c := q.modules[y][x]
+			if c {
+				dark++
+			}
+			if x > 0 && y > 0 && c == q.modules[y-1][x] && c == q.modules[y][x-1] && c == q.modules[y-1][x-1] {
+				result += 3
+			}
+		}
+	}
+
+	// отклонение доли темных модулей от 50%
+	total := q.size * q.size
+	result += abs(dark*20-total*10) / total * 10
+	return result
+}
+
+// reedSolomonDivisor возвращает порождающий многочлен степени degree над GF(2^8).
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= gfMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply умножает в поле GF(2^8) по модулю x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+type qrBitBuffer struct {
+	bits []bool
+}
+
+func (b *qrBitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		b.bits = append(b.bits, bit(value, i))
+	}
+}
+
+func (b *qrBitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *qrBitBuffer) bytes() []byte {
+	result := make([]byte, len(b.bits)/8)
+	for i, v := range b.bits {
+		if v {
+			result[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+	return result
+}
+
+func bit(value, i int) bool {
+	return (value>>i)&1 != 0
+}
+
+func boolsEqual(a, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,162 @@
+package bovasdk
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestEncodeQR tests Reed-Solomon codes, format and version bits, capacity and codeword placement of the QR encoder
+func TestEncodeQR(t *testing.T) {
+	// пример "HELLO WORLD" 1-M из стандарта
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := reedSolomonRemainder(data, reedSolomonDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("reedSolomonRemainder() = %v, want %v", got, want)
+	}
+
+	if got := qrFormatBits(QRLevelL, 0); got != 0x77C4 {
+		t.Errorf("qrFormatBits(L, 0) = %#x, want 0x77c4", got)
+	}
+	if got := qrFormatBits(QRLevelH, 7); got != 0x083B {
+		t.Errorf("qrFormatBits(H, 7) = %#x, want 0x083b", got)
+	}
+	if got := qrVersionBits(7); got != 0x07C94 {
+		t.Errorf("qrVersionBits(7) = %#x, want 0x7c94", got)
+	}
+
+	for version, want := range map[int][]int{2: {6, 18}, 7: {6, 22, 38}, 32: {6, 34, 60, 86, 112, 138}, 40: {6, 30, 58, 86, 114, 142, 170}} {
+		if got := qrAlignmentPositions(version); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("qrAlignmentPositions(%d) = %v, want %v", version, got, want)
+		}
+	}
+
+	// емкость байтового режима из таблицы стандарта
+	for _, c := range []struct {
+		level    QRLevel
+		capacity int
+		version  int
+	}{
+		{QRLevelM, 14, 1},
+		{QRLevelH, 7, 1},
+		{QRLevelL, 2953, 40},
+		{QRLevelH, 1273, 40},
+	} {
+		code, err := encodeQR(bytes.Repeat([]byte("a"), c.capacity), c.level)
+		if err != nil || code.version != c.version {
+			t.Errorf("encodeQR(%d bytes, %v) version = %v, %v, want %d", c.capacity, c.level, code, err, c.version)
+		}
+		if c.version == 40 {
+			if _, err = encodeQR(bytes.Repeat([]byte("a"), c.capacity+1), c.level); err == nil {
+				t.Errorf("encodeQR(%d bytes, %v) expected error", c.capacity+1, c.level)
+			}
+		}
+	}
+
+	// читаем кодовые слова обратно, сняв маску, указанную в информации о формате
+	for _, content := range []string{"https://sandbox.bovatech.cc/form/9bb5f95f36e1e40d6b1376", string(bytes.Repeat([]byte("x"), 400))} {
+		code, err := encodeQR([]byte(content), QRLevelQ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		format := 0
+		for i := 0; i <= 5; i++ {
+			if code.dark(8, i) {
+				format |= 1 << i
+			}
+		}
+		mask := -1
+		for m := 0; m < 8; m++ {
+			if qrFormatBits(QRLevelQ, m)&0x3F == format {
+				mask = m
+			}
+		}
+		if mask < 0 {
+			t.Fatalf("format bits %#x do not match level Q", format)
+		}
+		if !code.dark(8, code.size-8) {
+			t.Error("dark module is not set")
+		}
+
+		codewords := code.addECCAndInterleave(qrDataBytes([]byte(content), code.version, QRLevelQ))
+
+		code.applyMask(mask)
+		read := &qrBitBuffer{}
+		for right := code.size - 1; right >= 1; right -= 2 {
+			if right == 6 {
+				right = 5
+			}
+			for vert := 0; vert < code.size; vert++ {
+				for j := 0; j < 2; j++ {
+					x, y := right-j, vert
+					if (right+1)&2 == 0 {
+						y = code.size - 1 - vert
+					}
+					if !code.function[y][x] {
+						read.bits = append(read.bits, code.dark(x, y))
+					}
+				}
+			}
+		}
+		// в конце могут остаться биты остатка, не входящие в кодовые слова
+		if got := read.bytes()[:len(codewords)]; !bytes.Equal(got, codewords) {
+			t.Errorf("codewords read from %d-%v matrix do not match encoded data", code.version, code.level)
+		}
+	}
+}
+
+// TestEncodeQRGolden tests the encoder against matrices in testdata/qr produced by github.com/skip2/go-qrcode
+// (v0.0.0-20200617195104-da1b6568686e, without border). The files record the mask chosen by that library,
+// since mask selection may differ between encoders.
+func TestEncodeQRGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "qr", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no QR fixtures found")
+	}
+	levels := map[string]QRLevel{"L": QRLevelL, "M": QRLevelM, "Q": QRLevelQ, "H": QRLevelH}
+
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			data, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			content := strings.TrimPrefix(lines[0], "content: ")
+			level, ok := levels[strings.TrimPrefix(lines[1], "level: ")]
+			mask, err := strconv.Atoi(strings.TrimPrefix(lines[2], "mask: "))
+			if !ok || err != nil {
+				t.Fatalf("invalid fixture header: %q", lines[:3])
+			}
+			want := lines[3:]
+
+			code, err := encodeQRMask([]byte(content), level, mask)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code.size != len(want) {
+				t.Fatalf("encodeQR() size = %d, want %d", code.size, len(want))
+			}
+			for y, row := range want {
+				var got strings.Builder
+				for x := 0; x < code.size; x++ {
+					if code.dark(x, y) {
+						got.WriteByte('#')
+					} else {
+						got.WriteByte('.')
+					}
+				}
+				if got.String() != row {
+					t.Fatalf("encodeQR() row %d = %s, want %s", y, got.String(), row)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package bovasdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type QuoteSource string
+
+const (
+	// QuoteFromAPI - расчет получен от Bova.
+	QuoteFromAPI QuoteSource = "api"
+	// QuoteFromSchedule - расчет выполнен локально по тарифам из договора.
+	QuoteFromSchedule QuoteSource = "schedule"
+)
+
+// QuoteRequest - параметры будущей транзакции для расчета комиссии. Тело запроса POST /v1/quotes
+// не описано в документации Bova и является предположением.
+type QuoteRequest struct {
+	Currency      CurrencyEnum      `json:"currency"`
+	PaymentMethod PaymentMethodEnum `json:"payment_method"`
+	Direction     Direction         `json:"direction"`
+	Amount        int               `json:"amount"`
+}
+
+// Quote - ожидаемые суммы транзакции до ее создания.
+// TotalAmount для пополнения - сумма к зачислению мерчанту (FiatAmount - комиссия),
+// для выплаты - сумма к списанию со счета (FiatAmount + комиссия).
+type Quote struct {
+	QuoteRequest
+	Rate              string      `json:"rate"`
+	FiatAmount        string      `json:"fiat_amount"`
+	ServiceCommission string      `json:"service_commission"`
+	TotalAmount       string      `json:"total_amount"`
+	Source            QuoteSource `json:"source"`
+}
+
+// QuoteResponse представляет тело ответа API для расчета комиссии. Формат ответа не подтвержден документацией Bova.
+type QuoteResponse struct {
+	ResultCode string `json:"result_code"`
+	Payload    Quote  `json:"payload"`
+}
+
+// CommissionRule - тариф из договора для валюты, метода и направления.
+// Комиссия равна FiatAmount * Percent / 100 + Fixed, но не меньше MinCommission. Пустые поля равны 0, Rate по умолчанию 1.
+type CommissionRule struct {
+	Currency      CurrencyEnum
+	Method        PaymentMethodEnum
+	Direction     Direction
+	Percent       string
+	Fixed         string
+	MinCommission string
+	Rate          string
+}
+
+// FeeSchedule - локальный калькулятор комиссии по тарифам из договора.
+type FeeSchedule struct {
+	mu    sync.RWMutex
+	rules map[capabilityKey]CommissionRule
+}
+
+// NewFeeSchedule создает калькулятор с тарифами rules.
+func NewFeeSchedule(rules ...CommissionRule) *FeeSchedule {
+	s := &FeeSchedule{rules: make(map[capabilityKey]CommissionRule)}
+	for _, rule := range rules {
+		s.Set(rule)
+	}
+	return s
+}
+
+// Set добавляет или заменяет тариф.
+func (s *FeeSchedule) Set(rule CommissionRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[capabilityKey{rule.Currency, rule.Method, rule.Direction}] = rule
+}
+
+// Quote рассчитывает суммы транзакции по тарифу.
+func (s *FeeSchedule) Quote(req QuoteRequest) (*Quote, error) {
+	s.mu.RLock()
+	rule, ok := s.rules[capabilityKey{req.Currency, req.PaymentMethod, req.Direction}]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no commission rule for %s %s %s", req.Currency, req.PaymentMethod, req.Direction)
+	}
+
+	rate, err := parseRuleAmount(rule.Rate, "1")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing rate: %v", err)
+	}
+	percent, err := parseRuleAmount(rule.Percent, "0")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing percent: %v", err)
+	}
+	fixed, err := parseRuleAmount(rule.Fixed, "0")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing fixed commission: %v", err)
+	}
+	minCommission, err := parseRuleAmount(rule.MinCommission, "0")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing min commission: %v", err)
+	}
+
+	fiat := new(big.Rat).Mul(big.NewRat(int64(req.Amount), 1), rate)
+	commission := new(big.Rat).Mul(fiat, percent)
+	commission.Quo(commission, big.NewRat(100, 1)).Add(commission, fixed)
+	if commission.Cmp(minCommission) < 0 {
+		commission.Set(minCommission)
+	}
+	// округляем до копеек до расчета итога, как это делает API
+	fiat, _ = parseAmount(formatAmount(fiat))
+	commission, _ = parseAmount(formatAmount(commission))
+
+	total := new(big.Rat)
+	if req.Direction == DirectionPayout {
+		total.Add(fiat, commission)
+	} else {
+		total.Sub(fiat, commission)
+	}
+
+	return &Quote{
+		QuoteRequest:      req,
+		Rate:              rate.RatString(),
+		FiatAmount:        formatAmount(fiat),
+		ServiceCommission: formatAmount(commission),
+		TotalAmount:       formatAmount(total),
+		Source:            QuoteFromSchedule,
+	}, nil
+}
+
+// PayoutQuote рассчитывает суммы выплаты. Метод подходит в качестве PayoutQuoteFunc для Account.PreflightPayouts.
+func (s *FeeSchedule) PayoutQuote(req MassTransactionRequest) (*Quote, error) {
+	return s.Quote(QuoteRequest{Currency: req.Currency, PaymentMethod: req.PaymentMethod, Direction: DirectionPayout, Amount: req.Amount})
+}
+
+func parseRuleAmount(s, def string) (*big.Rat, error) {
+	if s == "" {
+		s = def
+	}
+	return parseAmount(s)
+}
+
+// quoteAPIRetryInterval - через сколько Quoter снова обращается к API после ответа, что эндпоинта расчета нет.
+const quoteAPIRetryInterval = 10 * time.Minute
+
+// Quoter рассчитывает комиссию через API Bova, а если API расчет не поддерживает - по локальным тарифам.
+// Эндпоинт POST /v1/quotes не описан в документации Bova и является предположением, поэтому для надежного
+// расчета задайте тарифы из договора в FeeSchedule.
+type Quoter struct {
+	transport *transport
+	schedule  *FeeSchedule
+	now       func() time.Time
+	// apiUnavailableUntil - время в UnixNano, до которого API не запрашивается, после того как оно ответило,
+	// что эндпоинта расчета нет
+	apiUnavailableUntil atomic.Int64
+}
+
+// NewQuoter создает Quoter. schedule может быть nil, тогда используется только API.
+func NewQuoter(api *BovaApi, schedule *FeeSchedule) *Quoter {
+	return &Quoter{transport: api.transport, schedule: schedule, now: time.Now}
+}
+
+// Quote возвращает ожидаемые суммы транзакции до ее создания. Если задан schedule, расчет по тарифам
+// используется при ответе API 404 или 405 и при любой ошибке, не являющейся ответом API, например
+// при ошибке сети или отказе Middleware.
+func (q *Quoter) Quote(ctx context.Context, req QuoteRequest, opts ...CallOption) (*Quote, error) {
+	if q.now().UnixNano() >= q.apiUnavailableUntil.Load() {
+		resp, err := execute[QuoteResponse](ctx, q.transport, apiCall{
+			method: http.MethodPost,
+			path:   "/v1/quotes",
+			json:   req,
+		}, opts...)
+		if err == nil {
+			quote := resp.Payload
+			quote.QuoteRequest = req
+			quote.Source = QuoteFromAPI
+			return &quote, nil
+		}
+		if q.schedule == nil {
+			return nil, err
+		}
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if apiErr.StatusCode != http.StatusNotFound && apiErr.StatusCode != http.StatusMethodNotAllowed {
+				return nil, err
+			}
+			q.apiUnavailableUntil.Store(q.now().Add(quoteAPIRetryInterval).UnixNano())
+		}
+	}
+
+	if q.schedule == nil {
+		return nil, fmt.Errorf("quote endpoint is not available and no fee schedule is configured")
+	}
+	return q.schedule.Quote(req)
+}
+
+// QuoteMismatch - расхождение фактических сумм транзакции с расчетом.
+type QuoteMismatch struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// VerifyP2P сравнивает суммы созданной p2p транзакции с расчетом и возвращает расхождения.
+func (q *Quote) VerifyP2P(tx P2PTransactionPayload) []QuoteMismatch {
+	return q.verify(tx.FiatAmount, tx.ServiceCommission, tx.TotalAmount)
+}
+
+// VerifyPayout сравнивает суммы созданной выплаты с расчетом и возвращает расхождения.
+func (q *Quote) VerifyPayout(tx MassTransactionPayload) []QuoteMismatch {
+	return q.verify(tx.FiatAmount, tx.ServiceCommission, tx.TotalAmount)
+}
+
+func (q *Quote) verify(fiatAmount, commission, totalAmount string) []QuoteMismatch {
+	var mismatches []QuoteMismatch
+	for _, c := range []struct{ field, expected, actual string }{
+		{"fiat_amount", q.FiatAmount, fiatAmount},
+		{"service_commission", q.ServiceCommission, commission},
+		{"total_amount", q.TotalAmount, totalAmount},
+	} {
+		if !amountsEqual(c.expected, c.actual) {
+			mismatches = append(mismatches, QuoteMismatch{Field: c.field, Expected: c.expected, Actual: c.actual})
+		}
+	}
+	return mismatches
+}
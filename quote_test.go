@@ -0,0 +1,68 @@
+package bovasdk_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestQuoter tests local quotes, API quotes and verification of created transactions
+func TestQuoter(t *testing.T) {
+	schedule := bovasdk.NewFeeSchedule(
+		bovasdk.CommissionRule{Currency: bovasdk.RUB, Method: bovasdk.Card, Direction: bovasdk.DirectionPayout, Percent: "1.5", Fixed: "10"},
+		bovasdk.CommissionRule{Currency: bovasdk.RUB, Method: bovasdk.Sbp, Direction: bovasdk.DirectionDeposit, Percent: "2", MinCommission: "50"},
+	)
+
+	// mock сервер не поддерживает расчет, используется локальный тариф
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	quoter := bovasdk.NewQuoter(newTestSDK(t, server), schedule)
+	ctx := context.Background()
+
+	payout, err := quoter.Quote(ctx, bovasdk.QuoteRequest{Currency: bovasdk.RUB, PaymentMethod: bovasdk.Card, Direction: bovasdk.DirectionPayout, Amount: 1000})
+	if err != nil {
+		t.Fatalf("Quote() error = %v", err)
+	}
+	if payout.Source != bovasdk.QuoteFromSchedule || payout.ServiceCommission != "25.00" || payout.TotalAmount != "1025.00" {
+		t.Errorf("Quote(payout) = %+v", payout)
+	}
+
+	deposit, err := quoter.Quote(ctx, bovasdk.QuoteRequest{Currency: bovasdk.RUB, PaymentMethod: bovasdk.Sbp, Direction: bovasdk.DirectionDeposit, Amount: 1000})
+	if err != nil || deposit.ServiceCommission != "50.00" || deposit.TotalAmount != "950.00" {
+		t.Errorf("Quote(deposit) = %+v, %v, want minimal commission", deposit, err)
+	}
+
+	if _, err = quoter.Quote(ctx, bovasdk.QuoteRequest{Currency: bovasdk.UZS, PaymentMethod: bovasdk.Card, Direction: bovasdk.DirectionPayout, Amount: 1}); err == nil {
+		t.Error("Quote() without rule error = nil")
+	}
+
+	var tx bovasdk.MassTransactionPayload
+	tx.FiatAmount, tx.ServiceCommission, tx.TotalAmount = "1000", "30.00", "1030.00"
+	mismatches := payout.VerifyPayout(tx)
+	if len(mismatches) != 2 || mismatches[0].Field != "service_commission" || mismatches[1].Field != "total_amount" {
+		t.Errorf("VerifyPayout() = %+v", mismatches)
+	}
+
+	req := *bovasdk.NewMassTransactionRequest("user", "p-1", "4111111111111111", "https://example.com", 1000, bovasdk.RUB, bovasdk.Card)
+	if q, err := schedule.PayoutQuote(req); err != nil || q.ServiceCommission != "25.00" {
+		t.Errorf("PayoutQuote() = %+v, %v", q, err)
+	}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result_code":"ok","payload":{"rate":"1","fiat_amount":"1000","service_commission":"20","total_amount":"1020"}}`))
+	}))
+	defer api.Close()
+	logger, _ := bovasdk.NewLogger(false, "error")
+	sdk, err := bovasdk.NewBovaApiBuilder().ApiURL(api.URL).Secret(testSecret).Logger(logger).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromAPI, err := bovasdk.NewQuoter(sdk, schedule).Quote(ctx, bovasdk.QuoteRequest{Currency: bovasdk.RUB, PaymentMethod: bovasdk.Card, Direction: bovasdk.DirectionPayout, Amount: 1000})
+	if err != nil || fromAPI.Source != bovasdk.QuoteFromAPI || fromAPI.ServiceCommission != "20" || fromAPI.Amount != 1000 {
+		t.Errorf("Quote() from API = %+v, %v", fromAPI, err)
+	}
+}
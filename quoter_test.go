@@ -0,0 +1,58 @@
+package bovasdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestQuoterFallback tests that the quoter falls back to the fee schedule on refused requests
+// and retries the API after the unavailable mark expires
+func TestQuoterFallback(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result_code":"ok","payload":{"rate":"1","fiat_amount":"1000","service_commission":"20","total_amount":"1020"}}`))
+	}))
+	defer server.Close()
+
+	schedule := NewFeeSchedule(CommissionRule{Currency: RUB, Method: Card, Direction: DirectionPayout, Percent: "1"})
+	req := QuoteRequest{Currency: RUB, PaymentMethod: Card, Direction: DirectionPayout, Amount: 1000}
+	logger, _ := NewLogger(false, "error")
+	ctx := context.Background()
+
+	// ключ sandbox на стороннем URL: охранник окружения отклоняет POST до отправки
+	guarded, err := NewBovaApiBuilder().ApiURL(server.URL).Secret("secret").Logger(logger).SecretEnvironment(Sandbox).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote, err := NewQuoter(guarded, schedule).Quote(ctx, req)
+	if err != nil || quote.Source != QuoteFromSchedule || calls != 0 {
+		t.Errorf("Quote() with refused request = %+v, %v, calls = %d, want schedule quote", quote, err, calls)
+	}
+
+	sdk, err := NewBovaApiBuilder().ApiURL(server.URL).Secret("secret").Logger(logger).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	quoter := NewQuoter(sdk, schedule)
+	quoter.now = func() time.Time { return now }
+
+	if quote, err = quoter.Quote(ctx, req); err != nil || quote.Source != QuoteFromSchedule {
+		t.Errorf("Quote() after 404 = %+v, %v, want schedule quote", quote, err)
+	}
+	if quote, err = quoter.Quote(ctx, req); err != nil || quote.Source != QuoteFromSchedule || calls != 1 {
+		t.Errorf("Quote() while API is marked unavailable = %+v, %v, calls = %d", quote, err, calls)
+	}
+	now = now.Add(quoteAPIRetryInterval)
+	if quote, err = quoter.Quote(ctx, req); err != nil || quote.Source != QuoteFromAPI || calls != 2 {
+		t.Errorf("Quote() after retry interval = %+v, %v, calls = %d, want API quote", quote, err, calls)
+	}
+}
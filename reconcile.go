@@ -0,0 +1,236 @@
+package bovasdk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+type LedgerKind string
+type DiffKind string
+
+const (
+	LedgerP2P    LedgerKind = "p2p"
+	LedgerPayout LedgerKind = "payout"
+
+	// DiffMissing - транзакция есть в локальном реестре, но не найдена в Bova.
+	DiffMissing DiffKind = "missing"
+	// DiffAmountMismatch - расходится сумма, фиатная сумма или комиссия.
+	DiffAmountMismatch DiffKind = "amount_mismatch"
+	// DiffStateDrift - статус в Bova отличается от локального.
+	DiffStateDrift DiffKind = "state_drift"
+	// DiffUnexpectedSuccess - Bova считает транзакцию успешной, а локально она не успешна.
+	DiffUnexpectedSuccess DiffKind = "unexpected_success"
+	// DiffFetchError - не удалось получить транзакцию из Bova.
+	DiffFetchError DiffKind = "fetch_error"
+)
+
+// LedgerRecord представляет запись локального реестра для сверки с Bova.
+// Пустые поля сумм не сравниваются.
+type LedgerRecord struct {
+	Kind       LedgerKind           `json:"kind"`
+	ID         string               `json:"id"`
+	MerchantID string               `json:"merchant_id"`
+	State      TransactionStateEnum `json:"state"`
+	Amount     string               `json:"amount"`
+	FiatAmount string               `json:"fiat_amount"`
+	Commission string               `json:"commission"`
+}
+
+// ReconciliationDiff описывает одно расхождение между локальным реестром и Bova.
+type ReconciliationDiff struct {
+	Kind       DiffKind   `json:"kind"`
+	LedgerKind LedgerKind `json:"ledger_kind"`
+	ID         string     `json:"id"`
+	MerchantID string     `json:"merchant_id"`
+	Field      string     `json:"field,omitempty"`
+	Local      string     `json:"local,omitempty"`
+	Remote     string     `json:"remote,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// ReconciliationReport содержит результат сверки.
+type ReconciliationReport struct {
+	Checked int                  `json:"checked"`
+	Matched int                  `json:"matched"`
+	Diffs   []ReconciliationDiff `json:"diffs"`
+}
+
+// WriteJSON записывает отчет в формате JSON.
+func (r *ReconciliationReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV записывает расхождения отчета в формате CSV с заголовком.
+func (r *ReconciliationReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"kind", "ledger_kind", "id", "merchant_id", "field", "local", "remote", "error"}); err != nil {
+		return err
+	}
+	for _, d := range r.Diffs {
+		if err := cw.Write([]string{string(d.Kind), string(d.LedgerKind), d.ID, d.MerchantID, d.Field, d.Local, d.Remote, d.Error}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Reconciler сверяет локальный реестр транзакций с текущими данными Bova.
+type Reconciler struct {
+	p2p         *P2P
+	mt          *MassTransaction
+	concurrency int
+}
+
+// NewReconciler создает новый экземпляр Reconciler. По умолчанию выполняется до 4 запросов одновременно.
+func NewReconciler(api *BovaApi) *Reconciler {
+	return &Reconciler{p2p: api.P2P, mt: api.MassTransaction, concurrency: 4}
+}
+
+// WithConcurrency задает максимальное число одновременных запросов к API и возвращает обновленный Reconciler
+func (r *Reconciler) WithConcurrency(concurrency int) *Reconciler {
+	if concurrency > 0 {
+		r.concurrency = concurrency
+	}
+	return r
+}
+
+// reconciledTx - общее представление p2p и массовой транзакции для сравнения.
+type reconciledTx struct {
+	state      TransactionStateEnum
+	amount     string
+	fiatAmount string
+	commission string
+}
+
+// Reconcile получает текущее состояние каждой записи реестра и возвращает отчет о расхождениях.
+// Порядок расхождений в отчете соответствует порядку записей.
+func (r *Reconciler) Reconcile(ctx context.Context, records []LedgerRecord) (*ReconciliationReport, error) {
+	diffs := make([][]ReconciliationDiff, len(records))
+	sem := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+
+	for i := range records {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			diffs[i] = r.reconcileRecord(ctx, records[i])
+		}(i)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{Checked: len(records), Diffs: []ReconciliationDiff{}}
+	for _, d := range diffs {
+		if len(d) == 0 {
+			report.Matched++
+		}
+		report.Diffs = append(report.Diffs, d...)
+	}
+	return report, nil
+}
+
+func (r *Reconciler) reconcileRecord(ctx context.Context, rec LedgerRecord) []ReconciliationDiff {
+	newDiff := func(kind DiffKind) ReconciliationDiff {
+		return ReconciliationDiff{Kind: kind, LedgerKind: rec.Kind, ID: rec.ID, MerchantID: rec.MerchantID}
+	}
+
+	remote, err := r.fetch(ctx, rec)
+	if err != nil {
+		if IsNotFound(err) {
+			return []ReconciliationDiff{newDiff(DiffMissing)}
+		}
+		d := newDiff(DiffFetchError)
+		d.Error = err.Error()
+		return []ReconciliationDiff{d}
+	}
+
+	var diffs []ReconciliationDiff
+	if rec.State != remote.state {
+		kind := DiffStateDrift
+		if remote.state.IsSuccess() && !rec.State.IsSuccess() {
+			kind = DiffUnexpectedSuccess
+		}
+		d := newDiff(kind)
+		d.Field, d.Local, d.Remote = "state", string(rec.State), string(remote.state)
+		diffs = append(diffs, d)
+	}
+
+	amounts := []struct{ field, local, remote string }{
+		{"amount", rec.Amount, remote.amount},
+		{"fiat_amount", rec.FiatAmount, remote.fiatAmount},
+		{"commission", rec.Commission, remote.commission},
+	}
+	for _, a := range amounts {
+		if a.local == "" || amountsEqual(a.local, a.remote) {
+			continue
+		}
+		d := newDiff(DiffAmountMismatch)
+		d.Field, d.Local, d.Remote = a.field, a.local, a.remote
+		diffs = append(diffs, d)
+	}
+
+	return diffs
+}
+
+func (r *Reconciler) fetch(ctx context.Context, rec LedgerRecord) (*reconciledTx, error) {
+	if rec.Kind == LedgerPayout {
+		resp, err := r.mt.GetMassTransaction(ctx, rec.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &reconciledTx{
+			state:      TransactionStateEnum(resp.Payload.State),
+			amount:     resp.Payload.Amount,
+			fiatAmount: resp.Payload.FiatAmount,
+			commission: resp.Payload.ServiceCommission,
+		}, nil
+	}
+
+	resp, err := r.p2p.GetP2PTransaction(ctx, rec.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &reconciledTx{
+		state:      resp.Payload.State,
+		amount:     resp.Payload.Amount,
+		fiatAmount: resp.Payload.FiatAmount,
+		commission: resp.Payload.ServiceCommission,
+	}, nil
+}
+
+// amountsEqual сравнивает денежные суммы как десятичные числа, так что "100" и "100.00" равны.
+func amountsEqual(a, b string) bool {
+	ra, okA := new(big.Rat).SetString(normalizeAmount(a))
+	rb, okB := new(big.Rat).SetString(normalizeAmount(b))
+	if !okA || !okB {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	return ra.Cmp(rb) == 0
+}
+
+func normalizeAmount(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	if s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package bovasdk_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+const testSecret = "mock_api_secret"
+
+func newTestSDK(t *testing.T, server *bovatest.Server) *bovasdk.BovaApi {
+	t.Helper()
+	logger, err := bovasdk.NewLogger(false, "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk, err := bovasdk.NewBovaApiBuilder().
+		ApiURL(server.URL).
+		Secret(testSecret).
+		Logger(logger).
+		Build()
+	if err != nil {
+		t.Fatalf("Error building SDK: %v", err)
+	}
+	return sdk
+}
+
+// TestReconcile tests the Reconcile method
+func TestReconcile(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+
+	var p2pOK, p2pDrift bovasdk.P2PTransactionResponse
+	p2pOK.Payload.ID, p2pOK.Payload.State, p2pOK.Payload.Amount, p2pOK.Payload.ServiceCommission = "p2p-ok", bovasdk.Paid, "100.00", "2.5"
+	p2pDrift.Payload.ID, p2pDrift.Payload.State, p2pDrift.Payload.Amount = "p2p-drift", bovasdk.Failed, "100"
+	server.AddP2PTransaction(p2pOK)
+	server.AddP2PTransaction(p2pDrift)
+
+	var payout bovasdk.MassTransactionResponse
+	payout.Payload.ID, payout.Payload.State, payout.Payload.Amount, payout.Payload.FiatAmount = "mass-1", string(bovasdk.Successed), "500", "5000"
+	server.AddMassTransaction(payout)
+
+	records := []bovasdk.LedgerRecord{
+		{Kind: bovasdk.LedgerP2P, ID: "p2p-ok", State: bovasdk.Paid, Amount: "100", Commission: "2.50"},
+		{Kind: bovasdk.LedgerP2P, ID: "p2p-drift", State: bovasdk.WaitingPayment, Amount: "100"},
+		{Kind: bovasdk.LedgerP2P, ID: "p2p-unknown", State: bovasdk.Paid},
+		{Kind: bovasdk.LedgerPayout, ID: "mass-1", MerchantID: "order-1", State: bovasdk.WaitingPayment, Amount: "500", FiatAmount: "4999"},
+	}
+
+	report, err := bovasdk.NewReconciler(newTestSDK(t, server)).WithConcurrency(2).Reconcile(context.Background(), records)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if report.Checked != 4 || report.Matched != 1 {
+		t.Errorf("Reconcile() checked = %d, matched = %d, want 4 and 1", report.Checked, report.Matched)
+	}
+
+	want := []struct {
+		kind  bovasdk.DiffKind
+		id    string
+		field string
+	}{
+		{bovasdk.DiffStateDrift, "p2p-drift", "state"},
+		{bovasdk.DiffMissing, "p2p-unknown", ""},
+		{bovasdk.DiffUnexpectedSuccess, "mass-1", "state"},
+		{bovasdk.DiffAmountMismatch, "mass-1", "fiat_amount"},
+	}
+	if len(report.Diffs) != len(want) {
+		t.Fatalf("Reconcile() diffs = %+v, want %d diffs", report.Diffs, len(want))
+	}
+	for i, w := range want {
+		d := report.Diffs[i]
+		if d.Kind != w.kind || d.ID != w.id || d.Field != w.field {
+			t.Errorf("Reconcile() diff[%d] = %+v, want %+v", i, d, w)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err = report.WriteCSV(buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatalf("WriteCSV() produced invalid csv: %v", err)
+	}
+	if len(rows) != len(want)+1 {
+		t.Errorf("WriteCSV() rows = %d, want %d", len(rows), len(want)+1)
+	}
+}
@@ -0,0 +1,278 @@
+package bovasdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handler отправляет запрос к API и возвращает ответ.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware оборачивает отправку запроса, например для метрик, ретраев или дополнительных заголовков.
+type Middleware func(next Handler) Handler
+
+// BeforeSend создает Middleware, который вызывает fn перед отправкой запроса. Ошибка fn прерывает запрос.
+func BeforeSend(fn func(req *http.Request) error) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := fn(req); err != nil {
+				return nil, err
+			}
+			return next(req)
+		}
+	}
+}
+
+// AfterReceive создает Middleware, который вызывает fn после получения ответа. Ошибка fn возвращается вместо ответа.
+func AfterReceive(fn func(req *http.Request, resp *http.Response) error) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err != nil {
+				return nil, err
+			}
+			if err = fn(req, resp); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			return resp, nil
+		}
+	}
+}
+
+// SigningMiddleware подписывает JSON тело запроса заголовком Signature.
+func SigningMiddleware(encoder *Encoder) Middleware {
+	return BeforeSend(func(req *http.Request) error {
+		if req.GetBody == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+			return nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(signatureHeader, encoder.CalculateSignature(data))
+		return nil
+	})
+}
+
+// RetryMiddleware повторяет идемпотентные запросы (GET и HEAD) при ошибке сети, 429 и 5xx до attempts раз.
+// Пауза между попытками удваивается начиная с backoff, для 429 учитывается заголовок Retry-After.
+// Создание транзакций не повторяется, так как это может привести к дублям, для выплат используйте PayoutOutbox.
+func RetryMiddleware(attempts int, backoff time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return next(req)
+			}
+
+			delay := backoff
+			for attempt := 1; ; attempt++ {
+				resp, err := next(req)
+				if attempt >= attempts || !shouldRetry(resp, err) {
+					return resp, err
+				}
+
+				wait := delay
+				if resp != nil {
+					if after, ok := retryAfter(resp.Header); ok {
+						wait = after
+					}
+					resp.Body.Close()
+				}
+				delay *= 2
+
+				select {
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// retryAfter разбирает заголовок Retry-After в секундах.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// transport выполняет запросы к API через цепочку Middleware.
+type transport struct {
+	apiURL  string
+	handler Handler
+	// strictEnums - строгая проверка перечислений по умолчанию для вызовов
+	strictEnums bool
+	// sbpBanks - справочник для проверки банка СБП в выплатах, nil - проверка выключена
+	sbpBanks *BankDirectory
+	// capabilities - проверка запросов на создание по таблице возможностей по умолчанию для вызовов
+	capabilities bool
+}
+
+// newTransport строит цепочку: первый Middleware из списка вызывается первым,
+// подпись выполняется последней, чтобы подписывалось тело после всех изменений.
+func newTransport(apiURL string, encoder *Encoder, client *http.Client, middlewares ...Middleware) *transport {
+	handler := SigningMiddleware(encoder)(countAttempts(client.Do))
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return &transport{apiURL: apiURL, handler: handler}
+}
+
+// apiCall описывает вызов API. Тело задается либо через json, либо через setBody.
+type apiCall struct {
+	method string
+	path   string
+	query  url.Values
+	json   interface{}
+	// setBody устанавливает тело запроса, отличное от JSON, например multipart
+	setBody func(req *http.Request)
+}
+
+// execute выполняет вызов и декодирует JSON ответ в T.
+func execute[T any](ctx context.Context, t *transport, call apiCall, opts ...CallOption) (*T, error) {
+	_, respBody, err := t.do(ctx, call, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var response T
+	if err = json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("error Unmarshal response: %v", err)
+	}
+	if t.strict(newCallOptions(opts)) {
+		if err = checkEnums(&response); err != nil {
+			return nil, fmt.Errorf("error in response: %w", err)
+		}
+	}
+	return &response, nil
+}
+
+func (t *transport) strict(options *callOptions) bool {
+	if options.strictEnums != nil {
+		return *options.strictEnums
+	}
+	return t.strictEnums
+}
+
+// checkCapabilities возвращает true, если запрос на создание нужно проверить по таблице возможностей.
+func (t *transport) checkCapabilities(options *callOptions) bool {
+	if options.capabilityValidation != nil {
+		return *options.capabilityValidation
+	}
+	return t.capabilities
+}
+
+// sbpBankDirectory возвращает справочник для проверки банка СБП или nil, если проверка выключена.
+func (t *transport) sbpBankDirectory(options *callOptions) *BankDirectory {
+	switch {
+	case options.sbpBankValidation == nil:
+		return t.sbpBanks
+	case !*options.sbpBankValidation:
+		return nil
+	case t.sbpBanks != nil:
+		return t.sbpBanks
+	default:
+		return sbpBanks
+	}
+}
+
+// do выполняет вызов и возвращает ответ и его тело. Тело ответа в resp заменено на прочитанную копию.
+// Для кода, отличного от 200, возвращается ответ вместе с *APIError.
+func (t *transport) do(ctx context.Context, call apiCall, opts ...CallOption) (*http.Response, []byte, error) {
+	options := newCallOptions(opts)
+	attempts := new(int32)
+	start := time.Now()
+
+	if t.strict(options) {
+		if err := checkEnums(call.json); err != nil {
+			return nil, nil, fmt.Errorf("error in request: %w", err)
+		}
+	}
+
+	httpReq, err := t.newRequest(context.WithValue(ctx, attemptsKey{}, attempts), call)
+	if err != nil {
+		return nil, nil, err
+	}
+	// http.Client закрывает тело сам, но Middleware, например BeforeSend или проверка окружения,
+	// может отказать в отправке, и тогда тело с открытыми файлами закрывается здесь
+	if httpReq.Body != nil {
+		defer httpReq.Body.Close()
+	}
+
+	resp, err := t.handler(httpReq)
+	if err != nil {
+		options.setMeta(nil, nil, time.Since(start), attempts)
+		return nil, nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		options.setMeta(resp, nil, time.Since(start), attempts)
+		return nil, nil, fmt.Errorf("error reading response: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	options.setMeta(resp, respBody, time.Since(start), attempts)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, respBody, &APIError{StatusCode: resp.StatusCode, Body: respBody, Header: resp.Header}
+	}
+	return resp, respBody, nil
+}
+
+func (t *transport) newRequest(ctx context.Context, call apiCall) (*http.Request, error) {
+	url := t.apiURL + call.path
+	if len(call.query) > 0 {
+		url += "?" + call.query.Encode()
+	}
+
+	var jsonData []byte
+	if call.json != nil {
+		var err error
+		if jsonData, err = json.Marshal(call.json); err != nil {
+			return nil, fmt.Errorf("error marshaling request: %v", err)
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, call.method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	switch {
+	case jsonData != nil:
+		httpReq.Body = io.NopCloser(bytes.NewReader(jsonData))
+		httpReq.ContentLength = int64(len(jsonData))
+		httpReq.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(jsonData)), nil
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+	case call.setBody != nil:
+		call.setBody(httpReq)
+	}
+	return httpReq, nil
+}
@@ -0,0 +1,210 @@
+package bovasdk_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bovasdk "github.com/AlexanderMikhel/bva"
+	"github.com/AlexanderMikhel/bva/bovatest"
+)
+
+// TestMiddleware tests that user middleware runs for every call and signing is applied after it
+func TestMiddleware(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+
+	var sent, received int32
+	logger, _ := bovasdk.NewLogger(false, "error")
+	sdk, err := bovasdk.NewBovaApiBuilder().
+		ApiURL(server.URL).
+		Secret(testSecret).
+		Logger(logger).
+		Middleware(
+			bovasdk.BeforeSend(func(req *http.Request) error {
+				atomic.AddInt32(&sent, 1)
+				req.Header.Set("X-Request-Id", "test")
+				return nil
+			}),
+			bovasdk.AfterReceive(func(req *http.Request, resp *http.Response) error {
+				atomic.AddInt32(&received, 1)
+				return nil
+			}),
+		).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := bovasdk.NewMassTransactionRequest("user-uuid", "order-1", "4111111111111111", "https://example.com/callback", 100, bovasdk.RUB, bovasdk.Card)
+	created, err := sdk.MassTransaction.CreateMassTransaction(context.Background(), *req)
+	if err != nil {
+		t.Fatalf("CreateMassTransaction() error = %v", err)
+	}
+	if _, err = sdk.MassTransaction.GetMassTransaction(context.Background(), created.Payload.ID); err != nil {
+		t.Fatalf("GetMassTransaction() error = %v", err)
+	}
+
+	if sent != 2 || received != 2 {
+		t.Errorf("middleware calls = %d/%d, want 2/2", sent, received)
+	}
+}
+
+// TestBeforeSendClosesBody tests that a request refused by middleware closes the dispute file without sending it
+func TestBeforeSendClosesBody(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+
+	logger, _ := bovasdk.NewLogger(false, "error")
+	sdk, err := bovasdk.NewBovaApiBuilder().
+		ApiURL(server.URL).
+		Secret(testSecret).
+		Logger(logger).
+		Middleware(bovasdk.BeforeSend(func(req *http.Request) error {
+			return errors.New("refused")
+		})).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "proof.png")
+	if err = os.WriteFile(path, []byte("\x89PNG\r\n\x1a\nmock"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err = sdk.P2P.CreateP2PDispute(context.Background(), bovasdk.NewP2PDisputeRequest("tx-1", 100, "proof.png", file)); err == nil {
+		t.Fatal("CreateP2PDispute() error = nil, want refused")
+	}
+	if _, err = file.Read(make([]byte, 1)); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("file read after refused request error = %v, want file closed", err)
+	}
+}
+
+// TestRetryMiddleware tests that RetryMiddleware retries GET requests only
+func TestRetryMiddleware(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"payload":{"id":"p2p-1","state":"paid"}}`))
+	}))
+	defer server.Close()
+
+	logger, _ := bovasdk.NewLogger(false, "error")
+	sdk, err := bovasdk.NewBovaApiBuilder().
+		ApiURL(server.URL).
+		Secret(testSecret).
+		Logger(logger).
+		Middleware(bovasdk.RetryMiddleware(3, time.Millisecond)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := sdk.P2P.GetP2PTransaction(context.Background(), "p2p-1")
+	if err != nil || resp.Payload.State != bovasdk.Paid {
+		t.Fatalf("GetP2PTransaction() = %v, %v", resp, err)
+	}
+	if calls != 2 {
+		t.Errorf("GET calls = %d, want 2", calls)
+	}
+
+	_, err = sdk.P2P.CreateP2PTransaction(context.Background(), bovasdk.P2PTransactionRequest{})
+	if apiErr, ok := err.(*bovasdk.APIError); !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("CreateP2PTransaction() error = %v, want 503 without retry", err)
+	}
+	if calls != 3 {
+		t.Errorf("total calls = %d, want 3", calls)
+	}
+}
+
+// TestDo tests the raw Do method with JSON and multipart bodies
+func TestDo(t *testing.T) {
+	server := bovatest.NewServer(testSecret)
+	defer server.Close()
+	sdk := newTestSDK(t, server)
+	ctx := context.Background()
+
+	var created bovasdk.P2PTransactionResponse
+	resp, err := sdk.Do(ctx, http.MethodPost, "/v1/p2p_transactions",
+		[]byte(`{"user_uuid":"user-uuid","merchant_id":"order-1","amount":100,"callback_url":"https://example.com","currency":"rub","payment_method":"card"}`), &created)
+	if err != nil || resp.StatusCode != http.StatusOK || created.Payload.ID == "" {
+		t.Fatalf("Do(POST p2p) = %v, %+v, %v", resp, created, err)
+	}
+
+	form := bovasdk.NewMultipartForm().
+		WithField("transaction_id", created.Payload.ID).
+		WithField("p2p_dispute[amount]", "100").
+		WithFile("p2p_dispute[proof_image]", "proof.png", "image/png", strings.NewReader("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	var dispute bovasdk.P2PDisputeResponse
+	if _, err = sdk.Do(ctx, http.MethodPost, "/v1/p2p_disputes/from_client", form, &dispute); err != nil {
+		t.Fatalf("Do(POST dispute) error = %v", err)
+	}
+	if dispute.Data.ID == 0 {
+		t.Errorf("Do(POST dispute) = %+v, want created dispute", dispute)
+	}
+
+	resp, err = sdk.Do(ctx, http.MethodGet, "/v1/p2p_transactions/missing", nil, nil)
+	if !bovasdk.IsNotFound(err) || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Do(GET missing) = %v, %v, want 404 response and error", resp, err)
+	}
+}
+
+// TestWithResponseMeta tests that WithResponseMeta is filled for successful, failed and retried calls
+func TestWithResponseMeta(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		switch {
+		case r.URL.Path == "/v1/p2p_transactions/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case atomic.AddInt32(&calls, 1) == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(`{"payload":{"id":"p2p-1","state":"paid","new_field":1}}`))
+		}
+	}))
+	defer server.Close()
+
+	logger, _ := bovasdk.NewLogger(false, "error")
+	sdk, err := bovasdk.NewBovaApiBuilder().
+		ApiURL(server.URL).
+		Secret(testSecret).
+		Logger(logger).
+		Middleware(bovasdk.RetryMiddleware(2, time.Millisecond)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var meta bovasdk.ResponseMeta
+	if _, err = sdk.P2P.GetP2PTransaction(context.Background(), "p2p-1", bovasdk.WithResponseMeta(&meta)); err != nil {
+		t.Fatalf("GetP2PTransaction() error = %v", err)
+	}
+	if meta.StatusCode != http.StatusOK || meta.Attempts != 2 || meta.Header.Get("X-Request-Id") != "req-1" ||
+		!strings.Contains(string(meta.Body), "new_field") || meta.Latency <= 0 {
+		t.Errorf("meta = %+v, want 200 after 2 attempts with raw body", meta)
+	}
+
+	if _, err = sdk.P2P.GetP2PTransaction(context.Background(), "missing", bovasdk.WithResponseMeta(&meta)); !bovasdk.IsNotFound(err) {
+		t.Fatalf("GetP2PTransaction(missing) error = %v, want not found", err)
+	}
+	if meta.StatusCode != http.StatusNotFound || meta.Attempts != 1 {
+		t.Errorf("meta = %+v, want 404 after 1 attempt", meta)
+	}
+}
@@ -1,83 +1,55 @@
 package bovasdk
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 )
 
 type MassTransaction struct {
-	apiURL  string
-	client  *http.Client
-	encoder *Encoder
+	transport *transport
 }
 
-func massTransactionNew(apiURL string, encoder *Encoder, client *http.Client) *MassTransaction {
-	return &MassTransaction{apiURL: apiURL, encoder: encoder, client: client}
+func massTransactionNew(transport *transport) *MassTransaction {
+	return &MassTransaction{transport: transport}
 }
 
 // CreateMassTransaction создает заявку на выплату на карту.
-func (mt *MassTransaction) CreateMassTransaction(ctx context.Context, req MassTransactionRequest) (*MassTransactionResponse, error) {
-	jsonData, err := json.Marshal(req)
+// Для sbp и sbp_fast разобранный номер телефона в ToCard приводится к формату Bova, невозможный номер
+// отклоняется до отправки, а номер, который не похож на мобильный, отправляется как есть.
+// Название банка СБП проверяется по справочнику, если проверка включена, см. BovaApiBuilder.ValidateSbpBanks,
+// а запрос - по таблице возможностей, если включена проверка BovaApiBuilder.ValidateCapabilities.
+// С опцией WithMerchantIDRecovery после неоднозначной ошибки выплата ищется по req.MerchantID.
+func (mt *MassTransaction) CreateMassTransaction(ctx context.Context, req MassTransactionRequest, opts ...CallOption) (*MassTransactionResponse, error) {
+	// невозможный номер телефона будет отклонен, проверяем до отправки
+	if err := req.normalizePhone(); err != nil {
+		return nil, err
+	}
+	options := newCallOptions(opts)
+	if mt.transport.checkCapabilities(options) {
+		if err := req.ValidateCapability(); err != nil {
+			return nil, err
+		}
+	}
+	if directory := mt.transport.sbpBankDirectory(options); directory != nil {
+		if err := directory.resolveSbpBank(&req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := execute[MassTransactionResponse](ctx, mt.transport, apiCall{
+		method: http.MethodPost,
+		path:   "/v1/mass_transactions",
+		json:   req,
+	}, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %v", err)
+		return recoverByMerchantID(ctx, err, opts, req.MerchantID, mt.GetMassTransactionByMerchantID)
 	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1/mass_transactions", mt.apiURL), bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set(signatureHeader, mt.encoder.CalculateSignature(jsonData))
-
-	resp, err := mt.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-200 response code: %v, reason: %s", resp.StatusCode, string(respBody))
-	}
-
-	var response MassTransactionResponse
-	if err = json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("error Unmarshal response: %v", err)
-	}
-
-	return &response, nil
+	return resp, nil
 }
 
 // GetMassTransaction получает информацию о транзакции по её ID.
-func (mt *MassTransaction) GetMassTransaction(ctx context.Context, transactionID string) (*MassTransactionResponse, error) {
-	url := fmt.Sprintf("%s/v1/mass_transactions/%s", mt.apiURL, transactionID)
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
-	}
-
-	resp, err := mt.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-200 response code: %v, reason: %s", resp.StatusCode, string(respBody))
-	}
-
-	var response MassTransactionResponse
-	if err = json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("error Unmarshal response: %v", err)
-	}
-
-	return &response, nil
+func (mt *MassTransaction) GetMassTransaction(ctx context.Context, transactionID string, opts ...CallOption) (*MassTransactionResponse, error) {
+	return execute[MassTransactionResponse](ctx, mt.transport, apiCall{
+		method: http.MethodGet,
+		path:   "/v1/mass_transactions/" + transactionID,
+	}, opts...)
 }
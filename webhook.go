@@ -0,0 +1,57 @@
+package bovasdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// defaultWebhookMaxBodySize ограничивает размер тела callback'а, чтобы не читать в память произвольные данные.
+const defaultWebhookMaxBodySize = 1 << 20
+
+// WebhookFunc обрабатывает тело callback'а с уже проверенной подписью.
+// Если функция вернула ошибку, Bova получит код 500 и повторит callback.
+type WebhookFunc func(ctx context.Context, body []byte) error
+
+// WebhookHandler принимает callback'и Bova на CallbackURL и проверяет их подпись.
+type WebhookHandler struct {
+	encoder     *Encoder
+	handle      WebhookFunc
+	maxBodySize int64
+}
+
+// NewWebhookHandler создает http.Handler для приема callback'ов.
+func NewWebhookHandler(encoder *Encoder, handle WebhookFunc) *WebhookHandler {
+	return &WebhookHandler{encoder: encoder, handle: handle, maxBodySize: defaultWebhookMaxBodySize}
+}
+
+// WithMaxBodySize задает максимальный размер тела callback'а и возвращает обновленный обработчик
+func (h *WebhookHandler) WithMaxBodySize(maxBodySize int64) *WebhookHandler {
+	h.maxBodySize = maxBodySize
+	return h
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if !h.encoder.VerifySignature(body, r.Header.Get(signatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	if err = h.handle(r.Context(), body); err != nil {
+		http.Error(w, "error handling callback", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,196 @@
+package bovasdk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type CallbackRejectReason string
+
+const (
+	// CallbackDuplicate - событие с тем же статусом и updated_at уже обработано.
+	CallbackDuplicate CallbackRejectReason = "duplicate"
+	// CallbackStale - уже обработано более позднее событие, например successed пришел раньше paid.
+	CallbackStale CallbackRejectReason = "stale"
+
+	// callbackGuardAttempts - число попыток CompareAndSwap при конкурентной доставке.
+	callbackGuardAttempts = 5
+)
+
+// CallbackRecord - последнее принятое событие по объекту.
+type CallbackRecord struct {
+	State     string `json:"state"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// CallbackStore хранит последнее принятое событие по каждому объекту.
+// Реализация для нескольких экземпляров сервиса может быть основана на Redis или SQL.
+type CallbackStore interface {
+	// Load возвращает последнее принятое событие или nil, если событий еще не было.
+	Load(ctx context.Context, key string) (*CallbackRecord, error)
+	// CompareAndSwap заменяет запись old на new, только если текущая запись равна old.
+	// nil в old означает отсутствие записи, nil в new - удаление записи.
+	CompareAndSwap(ctx context.Context, key string, old, new *CallbackRecord) (bool, error)
+}
+
+// memoryCallbackStore - хранилище CallbackStore в памяти процесса.
+type memoryCallbackStore struct {
+	mu      sync.Mutex
+	records map[string]CallbackRecord
+}
+
+// NewMemoryCallbackStore создает хранилище событий в памяти процесса.
+func NewMemoryCallbackStore() CallbackStore {
+	return &memoryCallbackStore{records: make(map[string]CallbackRecord)}
+}
+
+func (s *memoryCallbackStore) Load(_ context.Context, key string) (*CallbackRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	if !ok {
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+func (s *memoryCallbackStore) CompareAndSwap(_ context.Context, key string, old, new *CallbackRecord) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.records[key]
+	if ok != (old != nil) || (ok && current != *old) {
+		return false, nil
+	}
+	if new == nil {
+		delete(s.records, key)
+	} else {
+		s.records[key] = *new
+	}
+	return true, nil
+}
+
+// CallbackRejectedFunc вызывается для событий, отброшенных CallbackGuard.
+type CallbackRejectedFunc func(ctx context.Context, cb *Callback, reason CallbackRejectReason)
+
+// CallbackGuard отбрасывает повторные и устаревшие callback'и до вызова обработчика.
+type CallbackGuard struct {
+	store      CallbackStore
+	onRejected CallbackRejectedFunc
+}
+
+// NewCallbackGuard создает CallbackGuard. Если store равен nil, используется хранилище в памяти.
+func NewCallbackGuard(store CallbackStore) *CallbackGuard {
+	if store == nil {
+		store = NewMemoryCallbackStore()
+	}
+	return &CallbackGuard{store: store}
+}
+
+// WithOnRejected задает обработчик отброшенных событий и возвращает обновленный CallbackGuard
+func (g *CallbackGuard) WithOnRejected(onRejected CallbackRejectedFunc) *CallbackGuard {
+	g.onRejected = onRejected
+	return g
+}
+
+// Wrap возвращает обработчик, который вызывает next только для новых событий.
+// Событие помечается принятым до вызова next и снимается с отметки, если next вернул ошибку,
+// чтобы повторная доставка от Bova была обработана.
+func (g *CallbackGuard) Wrap(next CallbackFunc) CallbackFunc {
+	return func(ctx context.Context, cb *Callback) error {
+		key, rec := callbackRecordOf(cb)
+		if key == "" {
+			return next(ctx, cb)
+		}
+
+		var previous *CallbackRecord
+		claimed := false
+		for attempt := 0; attempt < callbackGuardAttempts && !claimed; attempt++ {
+			var err error
+			previous, err = g.store.Load(ctx, key)
+			if err != nil {
+				return fmt.Errorf("error loading callback record: %v", err)
+			}
+
+			if reason, rejected := rejectCallback(cb.Event, previous, rec); rejected {
+				if g.onRejected != nil {
+					g.onRejected(ctx, cb, reason)
+				}
+				return nil
+			}
+
+			if claimed, err = g.store.CompareAndSwap(ctx, key, previous, &rec); err != nil {
+				return fmt.Errorf("error saving callback record: %v", err)
+			}
+		}
+		if !claimed {
+			return fmt.Errorf("callback record for %s is concurrently modified", key)
+		}
+
+		if err := next(ctx, cb); err != nil {
+			_, _ = g.store.CompareAndSwap(ctx, key, &rec, previous)
+			return err
+		}
+		return nil
+	}
+}
+
+func callbackRecordOf(cb *Callback) (string, CallbackRecord) {
+	switch {
+	case cb.P2P != nil:
+		return string(CallbackP2P) + ":" + cb.P2P.ID, CallbackRecord{State: string(cb.P2P.State), UpdatedAt: cb.P2P.UpdatedAt}
+	case cb.Payout != nil:
+		return string(CallbackPayout) + ":" + cb.Payout.ID, CallbackRecord{State: string(cb.Payout.State), UpdatedAt: cb.Payout.UpdatedAt}
+	case cb.Dispute != nil:
+		return fmt.Sprintf("%s:%d", CallbackDispute, cb.Dispute.ID), CallbackRecord{State: string(cb.Dispute.State), UpdatedAt: cb.Dispute.UpdatedAt}
+	default:
+		return "", CallbackRecord{}
+	}
+}
+
+// rejectCallback решает, нужно ли отбросить событие next после уже принятого previous.
+func rejectCallback(event CallbackEvent, previous *CallbackRecord, next CallbackRecord) (CallbackRejectReason, bool) {
+	if previous == nil {
+		return "", false
+	}
+	if *previous == next {
+		return CallbackDuplicate, true
+	}
+
+	if previous.State == next.State {
+		if compareUpdatedAt(next.UpdatedAt, previous.UpdatedAt) <= 0 {
+			return CallbackStale, true
+		}
+		return "", false
+	}
+
+	var forward bool
+	if event == CallbackDispute {
+		forward = DisputeStateEnum(previous.State).CanTransitionTo(DisputeStateEnum(next.State))
+	} else {
+		forward = TransactionStateEnum(previous.State).CanTransitionTo(TransactionStateEnum(next.State))
+	}
+	if !forward {
+		return CallbackStale, true
+	}
+	return "", false
+}
+
+// compareUpdatedAt сравнивает метки времени Bova, при ошибке разбора сравнивает строки.
+func compareUpdatedAt(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return ta.Compare(tb)
+}
@@ -0,0 +1,70 @@
+package bovasdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func p2pCallback(id string, state TransactionStateEnum, updatedAt string) *Callback {
+	return &Callback{Event: CallbackP2P, P2P: &P2PCallback{ID: id, State: state, UpdatedAt: updatedAt}}
+}
+
+// TestCallbackGuard tests deduplication and ordering of callbacks
+func TestCallbackGuard(t *testing.T) {
+	var handled []TransactionStateEnum
+	var rejected []CallbackRejectReason
+	failNext := false
+
+	handler := NewCallbackGuard(nil).
+		WithOnRejected(func(ctx context.Context, cb *Callback, reason CallbackRejectReason) {
+			rejected = append(rejected, reason)
+		}).
+		Wrap(func(ctx context.Context, cb *Callback) error {
+			if failNext {
+				failNext = false
+				return errors.New("handler failed")
+			}
+			handled = append(handled, cb.P2P.State)
+			return nil
+		})
+
+	ctx := context.Background()
+	deliveries := []*Callback{
+		p2pCallback("tx-1", WaitingPayment, "2024-11-15T10:00:00+03:00"),
+		p2pCallback("tx-1", Successed, "2024-11-15T10:06:00+03:00"),
+		p2pCallback("tx-1", Paid, "2024-11-15T10:05:00+03:00"),
+		p2pCallback("tx-1", Successed, "2024-11-15T10:06:00+03:00"),
+		p2pCallback("tx-2", Paid, "2024-11-15T10:05:00+03:00"),
+	}
+	for _, cb := range deliveries {
+		if err := handler(ctx, cb); err != nil {
+			t.Fatalf("handler() error = %v", err)
+		}
+	}
+
+	wantHandled := []TransactionStateEnum{WaitingPayment, Successed, Paid}
+	if len(handled) != len(wantHandled) {
+		t.Fatalf("handled = %v, want %v", handled, wantHandled)
+	}
+	for i := range wantHandled {
+		if handled[i] != wantHandled[i] {
+			t.Errorf("handled = %v, want %v", handled, wantHandled)
+		}
+	}
+	if len(rejected) != 2 || rejected[0] != CallbackStale || rejected[1] != CallbackDuplicate {
+		t.Errorf("rejected = %v, want [stale duplicate]", rejected)
+	}
+
+	failNext = true
+	failed := p2pCallback("tx-2", Successed, "2024-11-15T10:07:00+03:00")
+	if err := handler(ctx, failed); err == nil {
+		t.Fatalf("handler() expected error")
+	}
+	if err := handler(ctx, failed); err != nil {
+		t.Fatalf("handler() redelivery error = %v", err)
+	}
+	if handled[len(handled)-1] != Successed || len(handled) != 4 {
+		t.Errorf("redelivered callback was not handled, handled = %v", handled)
+	}
+}
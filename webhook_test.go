@@ -0,0 +1,38 @@
+package bovasdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestWebhookHandler tests signature verification of the WebhookHandler
+func TestWebhookHandler(t *testing.T) {
+	encoder := NewEncoder("mock_api_secret")
+	body := `{"id":"p2p-1","state":"paid"}`
+
+	var received string
+	handler := NewWebhookHandler(encoder, func(ctx context.Context, body []byte) error {
+		received = string(body)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(body))
+	req.Header.Set(signatureHeader, encoder.CalculateSignature([]byte(body)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || received != body {
+		t.Errorf("ServeHTTP() code = %d, received = %q", rec.Code, received)
+	}
+
+	received = ""
+	req = httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(body))
+	req.Header.Set(signatureHeader, "invalid_signature")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || received != "" {
+		t.Errorf("ServeHTTP() with invalid signature code = %d, received = %q", rec.Code, received)
+	}
+}
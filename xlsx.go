@@ -0,0 +1,212 @@
+package bovasdk
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Минимальный читатель XLSX: поддерживаются только значения ячеек первого листа,
+// без формул, стилей и дат, чего достаточно для файлов выплат.
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var sb strings.Builder
+	for _, r := range t.Runs {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+const (
+	// xlsxMaxRowGap ограничивает число пустых строк между строками листа, чтобы атрибут r
+	// вроде "1048576" в испорченном файле не приводил к выделению памяти под миллион строк
+	xlsxMaxRowGap = 10000
+	// xlsxMaxColumns - максимальное число колонок листа Excel (XFD)
+	xlsxMaxColumns = 16384
+)
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSXFirstSheet(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err = decodeZipXML(f, &shared); err != nil {
+			return nil, fmt.Errorf("error decoding shared strings: %v", err)
+		}
+	}
+
+	sheetFile, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, fmt.Errorf("worksheet not found")
+	}
+	var sheet xlsxSheet
+	if err = decodeZipXML(sheetFile, &sheet); err != nil {
+		return nil, fmt.Errorf("error decoding worksheet: %v", err)
+	}
+
+	var records [][]string
+	for idx, row := range sheet.Rows {
+		rowNum := row.R
+		if rowNum == 0 {
+			rowNum = idx + 1
+		}
+		if rowNum < 1 || rowNum-len(records) > xlsxMaxRowGap {
+			return nil, fmt.Errorf("invalid row number %d after row %d", rowNum, len(records))
+		}
+		for len(records) < rowNum {
+			records = append(records, nil)
+		}
+
+		var record []string
+		for cellIdx, cell := range row.Cells {
+			col := cellIdx
+			if cell.Ref != "" {
+				if col, err = xlsxColumnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col >= xlsxMaxColumns {
+				return nil, fmt.Errorf("invalid cell reference: %s", cell.Ref)
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				n, err := strconv.Atoi(cell.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("invalid shared string index in cell %s", cell.Ref)
+				}
+				record[col] = shared.Items[n].String()
+			case "inlineStr":
+				record[col] = cell.Inline.String()
+			case "", "n":
+				record[col] = formatXLSXNumber(cell.Value)
+			default:
+				record[col] = cell.Value
+			}
+		}
+		records[rowNum-1] = record
+	}
+
+	return records, nil
+}
+
+// formatXLSXNumber записывает целое число без экспоненты: номер карты, сохраненный как число,
+// хранится в файле как "4.111111111111111E15". Дробные и нечисловые значения возвращаются как есть.
+func formatXLSXNumber(v string) string {
+	r, ok := new(big.Rat).SetString(v)
+	if !ok || !r.IsInt() {
+		return v
+	}
+	return r.Num().String()
+}
+
+// firstSheetPath находит путь к первому листу книги через workbook.xml, либо возвращает стандартный путь.
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return fallback
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback
+	}
+
+	var wb xlsxWorkbook
+	var rels xlsxRelationships
+	if decodeZipXML(wbFile, &wb) != nil || decodeZipXML(relsFile, &rels) != nil || len(wb.Sheets) == 0 {
+		return fallback
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// xlsxColumnIndex переводит ссылку на ячейку вида "AB12" в индекс колонки с нуля.
+func xlsxColumnIndex(ref string) (int, error) {
+	col := 0
+	for i, ch := range ref {
+		if ch >= 'A' && ch <= 'Z' {
+			col = col*26 + int(ch-'A'+1)
+			continue
+		}
+		if i == 0 {
+			break
+		}
+		return col - 1, nil
+	}
+	return 0, fmt.Errorf("invalid cell reference: %s", ref)
+}